@@ -0,0 +1,188 @@
+package porkbun
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ApplyOptions controls how ApplyZone reconciles a zone.
+type ApplyOptions struct {
+	// DryRun computes the diff without changing anything.
+	DryRun bool
+	// Prune deletes live records that are not in the desired set. Without
+	// it, extra records are only reported in the diff. The apex NS records
+	// are never pruned.
+	Prune bool
+	// ManagedTag, when set, restricts pruning to records whose notes carry a
+	// word starting with it (see TagManagedRecords and SplitByNotePrefix),
+	// leaving records created by other tools alone.
+	ManagedTag string
+	// Compare selects the fields that decide whether a paired record needs
+	// an edit, e.g. IgnoreTTL when syncing from another provider.
+	Compare DiffOptions
+}
+
+// ApplyResult holds the diff ApplyZone computed and the outcome of each
+// operation it performed, in order.
+type ApplyResult struct {
+	Diff    *RecordDiff
+	Results []BulkResult
+}
+
+// ApplyZone reconciles the live zone of domain towards desired: it deletes
+// (when pruning), then edits, then creates records. Edits keep the live TTL,
+// prio and notes where the desired record leaves them empty, or TTL "0", or
+// where opts.Compare ignores them. The error is
+// only set when the zone cannot be retrieved; failed operations are reported
+// in the results, and operations not yet run when ctx is cancelled fail with
+// the context's error.
+func (c *Client) ApplyZone(ctx context.Context, domain string, desired []*DNSRecord, opts ApplyOptions) (*ApplyResult, error) {
+	current, err := c.retrieveRecords(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	result := &ApplyResult{Diff: DiffRecordsWith(domain, current, desired, opts.Compare)}
+	if opts.DryRun {
+		return result, nil
+	}
+	if opts.Prune {
+		extra := result.Diff.Delete
+		if opts.ManagedTag != "" {
+			extra, _ = SplitByNotePrefix(extra, opts.ManagedTag)
+		}
+		for _, record := range extra {
+			if isApexNS(domain, record) {
+				continue
+			}
+			result.add(record.ID, record, ctxErrOr(ctx, func() error {
+				return c.deleteRecord(ctx, domain, record.ID)
+			}))
+		}
+	}
+	for _, update := range result.Diff.Update {
+		record := mergeRecord(update.Current, update.Desired, opts.Compare)
+		result.add(update.Current.ID, record, ctxErrOr(ctx, func() error {
+			return c.editRecord(ctx, domain, update.Current.ID, record)
+		}))
+	}
+	for _, record := range result.Diff.Create {
+		var id string
+		err := ctxErrOr(ctx, func() (err error) {
+			id, err = c.createRecord(ctx, domain, record)
+			return err
+		})
+		result.add(id, record, err)
+	}
+	return result, nil
+}
+
+// VerifyOptions controls what VerifyZone reports as drift.
+type VerifyOptions struct {
+	// Compare selects the compared fields, e.g. IgnoreTTL.
+	Compare DiffOptions
+	// IgnoreExtra leaves live records absent from the expected set
+	// unreported, for probes that only watch the records they know of.
+	IgnoreExtra bool
+}
+
+// VerifyResult lists how the live zone drifted from the expected records:
+// records that are Missing, live records that are Extra, and Mismatched
+// pairs of a live and an expected record at the same name and type that
+// differ in content or another compared field.
+type VerifyResult struct {
+	Missing    []*DNSRecord
+	Extra      []*DNSRecord
+	Mismatched []RecordUpdate
+}
+
+// OK reports whether the zone matches the expected records.
+func (r *VerifyResult) OK() bool {
+	return len(r.Missing) == 0 && len(r.Extra) == 0 && len(r.Mismatched) == 0
+}
+
+// VerifyZone compares the live zone of domain with expected without changing
+// anything, the monitoring counterpart of ApplyZone: what ApplyZone would
+// create is missing, what it would edit mismatched and what it would prune
+// extra. The apex NS records are never extra.
+func (c *Client) VerifyZone(domain string, expected []*DNSRecord, opts VerifyOptions) (*VerifyResult, error) {
+	current, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	diff := DiffRecordsWith(domain, current, expected, opts.Compare)
+	result := &VerifyResult{Missing: diff.Create, Mismatched: diff.Update}
+	if !opts.IgnoreExtra {
+		for _, record := range diff.Delete {
+			if !isApexNS(domain, record) {
+				result.Extra = append(result.Extra, record)
+			}
+		}
+	}
+	return result, nil
+}
+
+// SyncFromFile reads the desired records of domain from a file and applies
+// them with ApplyZone. Files ending in .json are read with
+// UnmarshalRecords, anything else as a zone file with ParseZoneFile.
+func (c *Client) SyncFromFile(ctx context.Context, domain string, path string, opts ApplyOptions) (*ApplyResult, error) {
+	desired, err := readRecordsFile(path, domain)
+	if err != nil {
+		return nil, err
+	}
+	return c.ApplyZone(ctx, domain, desired, opts)
+}
+
+func readRecordsFile(path string, domain string) ([]*DNSRecord, error) {
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return UnmarshalRecords(data)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseZoneFile(f, domain)
+}
+
+func (r *ApplyResult) add(id string, record *DNSRecord, err error) {
+	r.Results = append(r.Results, BulkResult{ID: id, Record: record, Err: err})
+}
+
+// isApexNS reports whether record delegates the zone itself, which
+// ApplyZone never prunes.
+func isApexNS(domain string, record *DNSRecord) bool {
+	return strings.EqualFold(record.Type, "NS") && nameKey(record.Name, domain) == ""
+}
+
+// mergeRecord returns desired with the TTL, prio and notes of current
+// filled in where desired leaves them empty, or where opts ignores them in
+// the comparison. A desired TTL of "0", which leaves the TTL to Porkbun,
+// also keeps the current one, so an unrelated edit does not reset it.
+func mergeRecord(current *DNSRecord, desired *DNSRecord, opts DiffOptions) *DNSRecord {
+	merged := *desired
+	if opts.IgnoreTTL || merged.TTL == "" || merged.TTL == "0" {
+		merged.TTL = current.TTL
+	}
+	if opts.IgnorePrio || merged.Prio == "" {
+		merged.Prio = current.Prio
+	}
+	if opts.IgnoreNotes || merged.Notes == "" {
+		merged.Notes = current.Notes
+	}
+	return &merged
+}
+
+// ctxErrOr runs fn unless ctx is already done, in which case it returns the
+// context's error.
+func ctxErrOr(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return fn()
+}
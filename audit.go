@@ -0,0 +1,65 @@
+package porkbun
+
+import (
+	"context"
+	"time"
+)
+
+// Operations reported in an AuditEvent.
+const (
+	AUDIT_OP_CREATE = "create"
+	AUDIT_OP_EDIT   = "edit"
+	AUDIT_OP_DELETE = "delete"
+)
+
+// AuditEvent describes a successful mutation, for Config.AuditHook. Before
+// is nil for creates, and when the previous state could not be retrieved;
+// After is nil for deletes and holds the record as submitted otherwise. The
+// records are copies the hook is free to keep or modify.
+type AuditEvent struct {
+	Operation string
+	Domain    string
+	ID        string
+	Before    *DNSRecord
+	After     *DNSRecord
+	Time      time.Time
+}
+
+// auditBefore fetches the state of a record about to be changed, if an
+// audit hook wants it.
+func (c *Client) auditBefore(ctx context.Context, domain string, id string) *DNSRecord {
+	if c.config.AuditHook == nil {
+		return nil
+	}
+	record, err := c.retrieveRecord(ctx, domain, id)
+	if err != nil {
+		return nil
+	}
+	return record
+}
+
+func (c *Client) audit(op string, domain string, id string, before *DNSRecord, after *DNSRecord) {
+	if c.config.AuditHook == nil {
+		return
+	}
+	event := AuditEvent{
+		Operation: op,
+		Domain:    domain,
+		ID:        id,
+		Before:    copyRecord(before),
+		After:     copyRecord(after),
+		Time:      c.clock().Now(),
+	}
+	if event.After != nil {
+		event.After.ID = id
+	}
+	c.config.AuditHook(event)
+}
+
+func copyRecord(r *DNSRecord) *DNSRecord {
+	if r == nil {
+		return nil
+	}
+	dup := *r
+	return &dup
+}
@@ -0,0 +1,43 @@
+package porkbun
+
+import (
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestAuditedWritesRetrieveOnce(t *testing.T) {
+	zone := newFakeZone("example.com",
+		&DNSRecord{Name: "_acme-challenge", Type: "TXT", Content: "token-1"},
+		&DNSRecord{Name: "_acme-challenge", Type: "TXT", Content: "token-2"},
+	)
+	var mu sync.Mutex
+	var events []AuditEvent
+	s, c := newTestServer(t, &Config{
+		AllowedTypes: []string{"TXT"},
+		AuditHook: func(e AuditEvent) {
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		},
+	}, zone.ServeHTTP)
+
+	if err := c.EditRecord("example.com", "1001", &DNSRecord{Name: "_acme-challenge", Type: "TXT", Content: "token-3"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteRecord("example.com", "1002"); err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, req := range s.Requests() {
+		paths = append(paths, req.Path)
+	}
+	want := "/dns/retrieve/example.com/1001 /dns/edit/example.com/1001 /dns/retrieve/example.com/1002 /dns/delete/example.com/1002"
+	if got := strings.Join(paths, " "); got != want {
+		t.Errorf("requests %s, want one retrieve per write:\n%s", got, want)
+	}
+	if len(events) != 2 || events[0].Before == nil || events[0].Before.Content != "token-1" ||
+		events[1].Before == nil || events[1].Before.Content != "token-2" {
+		t.Errorf("events = %+v, want the retrieved records as Before", events)
+	}
+}
@@ -0,0 +1,99 @@
+package porkbun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ZoneBackup is a snapshot of every record of a domain, taken by BackupZone.
+// It marshals to JSON for storage.
+type ZoneBackup struct {
+	Domain  string       `json:"domain"`
+	Time    time.Time    `json:"time"`
+	Records []*DNSRecord `json:"records"`
+}
+
+// RestoreOptions controls how RestoreZone reconciles a zone.
+type RestoreOptions struct {
+	// DryRun previews the changes without making them.
+	DryRun bool
+	// DeleteExtra deletes live records that are not in the backup. The apex
+	// NS records are always kept.
+	DeleteExtra bool
+}
+
+// RestoreResult holds the changes RestoreZone computed and the outcome of
+// each operation it performed.
+type RestoreResult struct {
+	Diff    *RecordDiff
+	Results []BulkResult
+}
+
+// BackupZone retrieves every record of domain into a ZoneBackup.
+func (c *Client) BackupZone(domain string) (*ZoneBackup, error) {
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	return &ZoneBackup{Domain: domain, Time: c.clock().Now().UTC(), Records: records}, nil
+}
+
+// RestoreZone reconciles domain towards the state in backup: missing records
+// are created, changed ones edited back, including their TTL, prio and
+// notes, and with DeleteExtra records added since are deleted. The API has
+// no transactions, so a failed operation leaves the zone partly restored;
+// failures are reported in the results, and running it again picks up where
+// it stopped. The error is set when the zone cannot be retrieved or the
+// backup is of another domain.
+func (c *Client) RestoreZone(domain string, backup *ZoneBackup, opts RestoreOptions) (*RestoreResult, error) {
+	if backup.Domain != "" && !strings.EqualFold(strings.TrimSuffix(backup.Domain, "."), strings.TrimSuffix(domain, ".")) {
+		return nil, fmt.Errorf("backup is of %s, not %s", backup.Domain, domain)
+	}
+	applied, err := c.ApplyZone(context.Background(), domain, backup.Records, ApplyOptions{
+		DryRun: opts.DryRun,
+		Prune:  opts.DeleteExtra,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RestoreResult{Diff: applied.Diff, Results: applied.Results}, nil
+}
+
+// BackupZoneTo writes every record of domain to w as JSON lines, one record
+// per line, a format that streams and appends cleanly to object storage.
+func (c *Client) BackupZoneTo(domain string, w io.Writer) error {
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	for _, record := range records {
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreZoneFrom reads records written by BackupZoneTo from r and restores
+// domain to them like RestoreZone.
+func (c *Client) RestoreZoneFrom(domain string, r io.Reader, opts RestoreOptions) (*RestoreResult, error) {
+	var records []*DNSRecord
+	dec := json.NewDecoder(r)
+	for line := 1; ; line++ {
+		record := &DNSRecord{}
+		err := dec.Decode(record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("backup record %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return c.RestoreZone(domain, &ZoneBackup{Records: records}, opts)
+}
@@ -3,4 +3,10 @@ package porkbun
 // Currently porkbun uses only POST methods for all APIs
 const PORKBUN_HTTP_METHOD = "POST"
 
+// Every request body is JSON; Config.ContentType can add parameters such as
+// a charset.
+const PORKBUN_CONTENT_TYPE = "application/json"
+
 const PORKBUN_API_BASE = "https://porkbun.com/api/json/v3"
+
+const PORKBUN_USER_AGENT = "porkbun-go"
@@ -0,0 +1,477 @@
+package porkbun
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// BulkResult is the outcome of one operation performed by a bulk helper.
+// ID is the record operated on (or created) and Err is nil on success.
+type BulkResult struct {
+	ID     string
+	Record *DNSRecord
+	Err    error
+}
+
+// BulkOptions controls how the bulk create helpers handle failures and
+// long runs.
+type BulkOptions struct {
+	// StopOnFirstError aborts at the first failing operation, returning the
+	// results so far, which end with the failure, and its error. Without it
+	// every operation is attempted and failures are only reported in the
+	// results.
+	StopOnFirstError bool
+	// ChunkSize splits the work into chunks of that many operations; zero
+	// means a single chunk. Progress is reported and the context checked
+	// between chunks.
+	ChunkSize int
+	// Progress, when set, is called after every chunk with the number of
+	// operations done so far and the total.
+	Progress func(done int, total int)
+}
+
+// BulkCreateRecords creates records in domain in order, reporting the
+// outcome of each. By default it continues past failures and the error is
+// nil; see BulkOptions.StopOnFirstError for strict imports.
+func (c *Client) BulkCreateRecords(domain string, records []*DNSRecord, opts BulkOptions) ([]BulkResult, error) {
+	return c.BulkCreateRecordsContext(context.Background(), domain, records, opts)
+}
+
+// CreateARecords creates an A record for every host of hosts, a map of
+// subdomain ("" for the apex) to IPv4 address, with the given TTL or the
+// default when ttl is 0. Hosts are created in name order and failures are
+// reported in the results; the error is only set when an entry is invalid,
+// in which case nothing is created.
+func (c *Client) CreateARecords(domain string, hosts map[string]string, ttl int) ([]BulkResult, error) {
+	names := make([]string, 0, len(hosts))
+	for name := range hosts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	records := make([]*DNSRecord, 0, len(names))
+	for _, name := range names {
+		record := &DNSRecord{Name: name, Type: "A", Content: hosts[name]}
+		if ttl > 0 {
+			record.TTL = strconv.Itoa(ttl)
+		}
+		if err := record.Validate(); err != nil {
+			return nil, fmt.Errorf("host %q: %w", name, err)
+		}
+		records = append(records, record)
+	}
+	return c.BulkCreateRecords(domain, records, BulkOptions{})
+}
+
+// BulkCreateRecordsContext is BulkCreateRecords for long imports: when ctx
+// is cancelled it stops before the next chunk, returning the results so far
+// and the context's error.
+func (c *Client) BulkCreateRecordsContext(ctx context.Context, domain string, records []*DNSRecord, opts BulkOptions) ([]BulkResult, error) {
+	chunk := opts.ChunkSize
+	if chunk <= 0 {
+		chunk = len(records)
+	}
+	results := make([]BulkResult, 0, len(records))
+	for start := 0; start < len(records); start += chunk {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		end := start + chunk
+		if end > len(records) {
+			end = len(records)
+		}
+		for _, record := range records[start:end] {
+			id, err := c.createRecord(ctx, domain, record)
+			results = append(results, BulkResult{ID: id, Record: record, Err: err})
+			if err != nil && opts.StopOnFirstError {
+				return results, fmt.Errorf("creating %s record %q: %w", record.Type, record.Name, err)
+			}
+		}
+		if opts.Progress != nil {
+			opts.Progress(end, len(records))
+		}
+	}
+	return results, nil
+}
+
+// RetrieveRecordsMulti retrieves the records of every domain, running at
+// most concurrency requests at a time (values below 1 mean one at a time).
+// Successful domains are keyed in the first map and failed ones in the
+// second; a domain appears in exactly one of them. A failure of one domain,
+// including a malformed response that cannot be decoded, never stops the
+// others.
+func (c *Client) RetrieveRecordsMulti(ctx context.Context, domains []string, concurrency int) (map[string][]*DNSRecord, map[string]error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		records = make(map[string][]*DNSRecord)
+		errs    = make(map[string]error)
+		sem     = make(chan struct{}, concurrency)
+	)
+	for _, domain := range domains {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			errs[domain] = ctx.Err()
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(domain string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			recs, err := c.retrieveRecords(ctx, domain)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs[domain] = err
+				return
+			}
+			records[domain] = recs
+		}(domain)
+	}
+	wg.Wait()
+	return records, errs
+}
+
+// DeleteRecordsByContent deletes every record of the given type whose
+// content equals content, e.g. all A records pointing at a decommissioned
+// host. The error is only set when the zone cannot be retrieved; failures of
+// individual deletes are reported in the results.
+func (c *Client) DeleteRecordsByContent(domain string, recordType string, content string) ([]BulkResult, error) {
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	want := normalizeRecord(DNSRecord{Type: recordType, Content: content})
+	var results []BulkResult
+	for _, record := range records {
+		have := normalizeRecord(*record)
+		if !strings.EqualFold(have.Type, want.Type) || have.Content != want.Content {
+			continue
+		}
+		results = append(results, BulkResult{
+			ID:     record.ID,
+			Record: record,
+			Err:    c.DeleteRecord(domain, record.ID),
+		})
+	}
+	return results, nil
+}
+
+// DeleteRecordsByTypeExcept deletes every record of the given type whose
+// content is not in keepContents, e.g. all A records except the current
+// production IP. Content is compared in normalized form. An empty keep list
+// is refused so a mistake cannot wipe every record of the type. The error is
+// only set then or when the zone cannot be retrieved; failures of
+// individual deletes are reported in the results.
+func (c *Client) DeleteRecordsByTypeExcept(domain string, recordType string, keepContents []string) ([]BulkResult, error) {
+	if len(keepContents) == 0 {
+		return nil, fmt.Errorf("keep list must not be empty")
+	}
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	keep := make(map[string]bool, len(keepContents))
+	for _, content := range keepContents {
+		keep[normalizeRecord(DNSRecord{Type: recordType, Content: content}).Content] = true
+	}
+	var results []BulkResult
+	for _, record := range records {
+		have := normalizeRecord(*record)
+		if !strings.EqualFold(have.Type, recordType) || keep[have.Content] {
+			continue
+		}
+		results = append(results, BulkResult{
+			ID:     record.ID,
+			Record: record,
+			Err:    c.DeleteRecord(domain, record.ID),
+		})
+	}
+	return results, nil
+}
+
+// TagManagedRecords marks every record of domain as managed by adding tag to
+// its notes, appending it after any existing notes. Records already carrying
+// the tag are left alone and not part of the results. The error is only set
+// when the zone cannot be retrieved.
+func (c *Client) TagManagedRecords(domain string, tag string) ([]BulkResult, error) {
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	var results []BulkResult
+	for _, record := range records {
+		if hasNoteTag(record.Notes, tag) {
+			continue
+		}
+		tagged := *record
+		tagged.Notes = strings.TrimSpace(record.Notes + " " + tag)
+		results = append(results, BulkResult{
+			ID:     record.ID,
+			Record: &tagged,
+			Err:    c.EditRecord(domain, record.ID, &tagged),
+		})
+	}
+	return results, nil
+}
+
+// RenameBySubdomainPrefix renames every record of domain whose subdomain
+// starts with oldPrefix so it starts with newPrefix instead, e.g. moving
+// "old-api" and "old-web" to "new-api" and "new-web", keeping all other
+// fields. Prefixes match case-insensitively. The error is only set when
+// oldPrefix is empty or the zone cannot be retrieved; failures of
+// individual edits are reported in the results.
+func (c *Client) RenameBySubdomainPrefix(domain string, oldPrefix string, newPrefix string) ([]BulkResult, error) {
+	if oldPrefix == "" {
+		return nil, fmt.Errorf("old prefix must not be empty")
+	}
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	var results []BulkResult
+	for _, record := range records {
+		name := relativeName(record.Name, domain)
+		if len(name) < len(oldPrefix) || !strings.EqualFold(name[:len(oldPrefix)], oldPrefix) {
+			continue
+		}
+		renamed := *record
+		renamed.Name = newPrefix + name[len(oldPrefix):]
+		results = append(results, BulkResult{
+			ID:     record.ID,
+			Record: &renamed,
+			Err:    c.EditRecord(domain, record.ID, &renamed),
+		})
+	}
+	return results, nil
+}
+
+// hasNoteTag reports whether tag appears as a whitespace-separated word in
+// notes.
+func hasNoteTag(notes string, tag string) bool {
+	for _, word := range strings.Fields(notes) {
+		if word == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// hasNotePrefix reports whether a whitespace-separated word of notes starts
+// with prefix, so a tag added by TagManagedRecords after existing notes still
+// counts.
+func hasNotePrefix(notes string, prefix string) bool {
+	for _, word := range strings.Fields(notes) {
+		if strings.HasPrefix(word, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitByNotePrefix separates the records whose notes carry a word starting
+// with prefix, the ones a reconciler owns, from all others. The order of
+// records is kept in both slices.
+func SplitByNotePrefix(records []*DNSRecord, prefix string) (managed []*DNSRecord, unmanaged []*DNSRecord) {
+	for _, record := range records {
+		if prefix != "" && hasNotePrefix(record.Notes, prefix) {
+			managed = append(managed, record)
+		} else {
+			unmanaged = append(unmanaged, record)
+		}
+	}
+	return managed, unmanaged
+}
+
+// PartitionByAlias separates ALIAS records, which Porkbun flattens into the
+// target's addresses at query time and which have no standard DNS
+// equivalent, from all others. Migrations usually have to turn an apex ALIAS
+// into something the new provider supports. The order of records is kept in
+// both slices.
+func PartitionByAlias(records []*DNSRecord) (standard []*DNSRecord, alias []*DNSRecord) {
+	for _, record := range records {
+		if strings.EqualFold(record.Type, "ALIAS") {
+			alias = append(alias, record)
+		} else {
+			standard = append(standard, record)
+		}
+	}
+	return standard, alias
+}
+
+// DeduplicateRecords deletes all but one record of every group sharing name,
+// type and content, keeping the first one the API returns. The results list
+// the removed records; with dryRun nothing is deleted and the results show
+// what would be. The error is only set when the zone cannot be retrieved.
+func (c *Client) DeduplicateRecords(domain string, dryRun bool) ([]BulkResult, error) {
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	var results []BulkResult
+	byName := groupByName(records, domain)
+	for _, name := range sortedKeys(byName) {
+		for _, dups := range duplicateGroups(byName[name]) {
+			for _, record := range dups[1:] {
+				result := BulkResult{ID: record.ID, Record: record}
+				if !dryRun {
+					result.Err = c.DeleteRecord(domain, record.ID)
+				}
+				results = append(results, result)
+			}
+		}
+	}
+	return results, nil
+}
+
+// SetZoneTTL sets the TTL of every record of domain to ttl seconds, keeping
+// all other fields, e.g. to lower TTLs ahead of a migration. Records already
+// at that TTL are skipped. The ttl must be positive: 0, which elsewhere
+// leaves the TTL to Porkbun, is refused, since the TTL Porkbun would pick
+// is not known and every record would be edited on every call. The error is
+// only set when ttl is invalid or the zone cannot be retrieved.
+func (c *Client) SetZoneTTL(domain string, ttl int) ([]BulkResult, error) {
+	if ttl <= 0 {
+		return nil, fmt.Errorf("ttl must be positive, got %d", ttl)
+	}
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	want := strconv.Itoa(ttl)
+	var results []BulkResult
+	for _, record := range records {
+		if record.TTL == want {
+			continue
+		}
+		edited := *record
+		edited.TTL = want
+		results = append(results, BulkResult{
+			ID:     record.ID,
+			Record: &edited,
+			Err:    c.EditRecord(domain, record.ID, &edited),
+		})
+	}
+	return results, nil
+}
+
+// SwapRecordContent exchanges the content of two records, e.g. to cut
+// traffic over between a blue and a green target. The two edits are
+// separate calls, so for a moment both records carry the same content, and
+// if the second edit fails the first has already happened; the results show
+// which edits succeeded so callers can roll back. The error is only set when
+// a record cannot be retrieved.
+func (c *Client) SwapRecordContent(domain string, idA string, idB string) ([]BulkResult, error) {
+	a, err := c.RetrieveRecord(domain, idA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := c.RetrieveRecord(domain, idB)
+	if err != nil {
+		return nil, err
+	}
+	newA, newB := *a, *b
+	newA.Content, newB.Content = b.Content, a.Content
+	results := make([]BulkResult, 0, 2)
+	for _, edit := range []*DNSRecord{&newA, &newB} {
+		results = append(results, BulkResult{
+			ID:     edit.ID,
+			Record: edit,
+			Err:    c.EditRecord(domain, edit.ID, edit),
+		})
+	}
+	return results, nil
+}
+
+// ReplaceRecordSet makes records the complete set of recordType records at
+// subdomain ("" for the apex), as for round-robin or weighted sets. Every
+// entry keeps its own content, TTL and prio, so an SRV set can mix weights
+// ("weight port target") and priorities. Name and Type of the entries are
+// set from the arguments. Records already matching are left alone, others
+// are edited in place where possible, and the rest created or deleted;
+// deletes run last so the set never goes empty in between. The error is
+// only set when the current set cannot be retrieved.
+func (c *Client) ReplaceRecordSet(domain string, recordType string, subdomain string, records []*DNSRecord) ([]BulkResult, error) {
+	current, err := c.RetrieveRecordsByNameType(domain, recordType, subdomain)
+	if err != nil {
+		return nil, err
+	}
+	desired := make([]*DNSRecord, len(records))
+	for i, record := range records {
+		entry := *record
+		entry.Name, entry.Type = subdomain, recordType
+		desired[i] = &entry
+	}
+	return c.applyRecordSet(domain, current, desired), nil
+}
+
+// CompareAndSetSet replaces the contents of the recordType set at name, like
+// ReplaceRecordSet, but only if the live set still holds exactly expected,
+// in any order; otherwise it returns an error wrapping ErrConflict and
+// changes nothing. This gives tools racing over a round-robin set
+// optimistic concurrency, though a writer may still slip in between the
+// check and the changes. New records take the TTL of the live set. Types
+// with a priority, such as MX and SRV, are refused since plain content
+// cannot carry one per record; use ReplaceRecordSet for those.
+func (c *Client) CompareAndSetSet(domain string, name string, recordType string, expected []string, desired []string) ([]BulkResult, error) {
+	if hasPriority(recordType) {
+		return nil, fmt.Errorf("CompareAndSetSet cannot set the priorities of a %s set, use ReplaceRecordSet", recordType)
+	}
+	current, err := c.RetrieveRecordsByNameType(domain, recordType, name)
+	if err != nil {
+		return nil, err
+	}
+	live := make([]string, len(current))
+	for i, record := range current {
+		live[i] = normalizeRecord(*record).Content
+	}
+	want := make([]string, len(expected))
+	for i, content := range expected {
+		want[i] = normalizeRecord(DNSRecord{Type: recordType, Content: content}).Content
+	}
+	sort.Strings(live)
+	sort.Strings(want)
+	if strings.Join(live, "\n") != strings.Join(want, "\n") || len(live) != len(want) {
+		return nil, fmt.Errorf("%w: %s set at %q in %s is %q, expected %q", ErrConflict, recordType, name, domain, live, want)
+	}
+	records := make([]*DNSRecord, len(desired))
+	for i, content := range desired {
+		records[i] = &DNSRecord{Name: name, Type: recordType, Content: content}
+		if len(current) > 0 {
+			records[i].TTL = current[0].TTL
+		}
+	}
+	return c.applyRecordSet(domain, current, records), nil
+}
+
+// applyRecordSet turns the current records of one name and type into
+// desired, editing where possible and deleting last.
+func (c *Client) applyRecordSet(domain string, current []*DNSRecord, desired []*DNSRecord) []BulkResult {
+	diff := DiffRecords(domain, current, desired)
+	var results []BulkResult
+	for _, update := range diff.Update {
+		record := mergeRecord(update.Current, update.Desired, DiffOptions{})
+		results = append(results, BulkResult{
+			ID:     update.Current.ID,
+			Record: record,
+			Err:    c.EditRecord(domain, update.Current.ID, record),
+		})
+	}
+	for _, record := range diff.Create {
+		id, err := c.CreateRecord(domain, record)
+		results = append(results, BulkResult{ID: id, Record: record, Err: err})
+	}
+	for _, record := range diff.Delete {
+		results = append(results, BulkResult{ID: record.ID, Record: record, Err: c.DeleteRecord(domain, record.ID)})
+	}
+	return results
+}
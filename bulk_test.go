@@ -0,0 +1,114 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRetrieveRecordsMultiDecodeError(t *testing.T) {
+	_, c := newTestServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dns/retrieve/broken.example" {
+			respond(http.StatusOK, `{"status":"SUCCESS","records":[{"id":`)(w, r)
+			return
+		}
+		respond(http.StatusOK, retrievePayload)(w, r)
+	})
+	domains := []string{"example.com", "broken.example", "example.net"}
+	records, errs := c.RetrieveRecordsMulti(context.Background(), domains, 2)
+	if len(errs) != 1 || errs["broken.example"] == nil {
+		t.Errorf("errors %v, want one for broken.example", errs)
+	}
+	for _, domain := range []string{"example.com", "example.net"} {
+		if len(records[domain]) != 2 {
+			t.Errorf("%s: got %d records, want 2", domain, len(records[domain]))
+		}
+	}
+	if _, ok := records["broken.example"]; ok {
+		t.Error("broken.example is also among the successes")
+	}
+}
+
+func TestReplaceRecordSetWeightedSRV(t *testing.T) {
+	zone := newFakeZone("example.com",
+		&DNSRecord{Name: "_sip._tcp", Type: "SRV", Content: "10 5060 a.example.com", Prio: "10"},
+		&DNSRecord{Name: "_sip._tcp", Type: "SRV", Content: "10 5060 old.example.com", Prio: "10"},
+	)
+	_, c := newTestServer(t, nil, zone.ServeHTTP)
+	desired := []*DNSRecord{
+		{Content: "60 5060 a.example.com", Prio: "10"},
+		{Content: "30 5060 b.example.com", Prio: "10"},
+		{Content: "10 5060 c.example.com", Prio: "20", TTL: "3600"},
+	}
+	results, err := c.ReplaceRecordSet("example.com", "SRV", "_sip._tcp", desired)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("%+v: %v", result.Record, result.Err)
+		}
+	}
+	got := make(map[string]DNSRecord)
+	for _, record := range zone.Records() {
+		got[record.Content] = record
+	}
+	if len(got) != 3 {
+		t.Fatalf("zone holds %+v, want the three desired records", zone.Records())
+	}
+	for _, want := range desired {
+		record, ok := got[want.Content]
+		if !ok || record.Prio != want.Prio || record.Name != "_sip._tcp.example.com" || record.Type != "SRV" {
+			t.Errorf("zone has %+v for %q, want prio %s", record, want.Content, want.Prio)
+		}
+	}
+	if got["10 5060 c.example.com"].TTL != "3600" {
+		t.Errorf("per-entry TTL lost: %+v", got["10 5060 c.example.com"])
+	}
+}
+
+func TestCompareAndSetSet(t *testing.T) {
+	zone := newFakeZone("example.com",
+		&DNSRecord{Name: "www", Type: "A", Content: "192.0.2.1", TTL: "300"},
+		&DNSRecord{Name: "www", Type: "A", Content: "192.0.2.2", TTL: "300"},
+	)
+	s, c := newTestServer(t, nil, zone.ServeHTTP)
+
+	_, err := c.CompareAndSetSet("example.com", "www", "A", []string{"192.0.2.1"}, []string{"192.0.2.3"})
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("stale expected: got %v, want ErrConflict", err)
+	}
+	results, err := c.CompareAndSetSet("example.com", "www", "A", []string{"192.0.2.2", "192.0.2.1"}, []string{"192.0.2.1", "192.0.2.3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.Errorf("%s: %v", result.ID, result.Err)
+		}
+	}
+	var contents []string
+	for _, record := range zone.Records() {
+		if record.TTL != "300" {
+			t.Errorf("%s has TTL %s, want the set's 300", record.Content, record.TTL)
+		}
+		contents = append(contents, record.Content)
+	}
+	sort.Strings(contents)
+	if strings.Join(contents, " ") != "192.0.2.1 192.0.2.3" {
+		t.Errorf("zone = %v", contents)
+	}
+
+	sent := len(s.Requests())
+	for _, typ := range []string{"MX", "srv"} {
+		if _, err := c.CompareAndSetSet("example.com", "", typ, nil, []string{"mx.example.com"}); err == nil {
+			t.Errorf("%s: got no error, want priority types refused", typ)
+		}
+	}
+	if n := len(s.Requests()); n != sent {
+		t.Errorf("refused calls sent %d requests", n-sent)
+	}
+}
@@ -0,0 +1,42 @@
+package porkbun
+
+import (
+	"context"
+	"time"
+)
+
+// Clock is the source of time behind retries, waits, pacing and the
+// timestamps the client records. Config.Clock replaces the real clock, so
+// tests can advance time deterministically instead of sleeping.
+type Clock interface {
+	Now() time.Time
+	// After returns a channel receiving the time once d has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// clock returns Config.Clock, or the real clock when unset.
+func (c *Client) clock() Clock {
+	if c.config.Clock != nil {
+		return c.config.Clock
+	}
+	return realClock{}
+}
+
+// sleep waits for d to elapse on clock, returning ctx.Err() as soon as ctx
+// is done first.
+func sleep(ctx context.Context, clock Clock, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	select {
+	case <-clock.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,85 @@
+package porkbun
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+)
+
+// Environment variables read by NewClientFromEnv.
+const (
+	ENV_API_KEY        = "PORKBUN_API_KEY"
+	ENV_SECRET_API_KEY = "PORKBUN_SECRET_API_KEY"
+	ENV_BASE_URL       = "PORKBUN_BASE_URL"
+)
+
+// fileConfig is the on-disk format read by NewClientFromFile.
+type fileConfig struct {
+	APIKey       string `json:"apikey"`
+	SecretAPIKey string `json:"secretapikey"`
+	BaseURL      string `json:"baseurl,omitempty"`
+	Timeout      string `json:"timeout,omitempty"`
+}
+
+// NewClientFromFile builds a client from a JSON credentials file such as
+//
+//	{
+//	  "apikey": "pk1_...",
+//	  "secretapikey": "sk1_...",
+//	  "baseurl": "https://porkbun.com/api/json/v3",
+//	  "timeout": "30s"
+//	}
+//
+// where baseurl and timeout are optional. The file must not be readable by
+// other users, since it holds the secret key.
+func NewClientFromFile(path string) (*Client, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o007 != 0 {
+		return nil, fmt.Errorf("credentials file %s must not be accessible by other users (mode %v)", path, info.Mode().Perm())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var fc fileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("parsing credentials file %s: %w", path, err)
+	}
+	cfg := &Config{
+		Auth:    Auth{APIKey: fc.APIKey, SecretAPIKey: fc.SecretAPIKey},
+		BaseURL: fc.BaseURL,
+	}
+	if fc.Timeout != "" {
+		timeout, err := time.ParseDuration(fc.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("parsing timeout in %s: %w", path, err)
+		}
+		cfg.Timeout = timeout
+	}
+	return NewClient(cfg)
+}
+
+// NewClientFromEnv builds a client from the PORKBUN_API_KEY and
+// PORKBUN_SECRET_API_KEY environment variables, plus the optional
+// PORKBUN_BASE_URL.
+func NewClientFromEnv() (*Client, error) {
+	cfg := &Config{
+		Auth: Auth{
+			APIKey:       os.Getenv(ENV_API_KEY),
+			SecretAPIKey: os.Getenv(ENV_SECRET_API_KEY),
+		},
+		BaseURL: os.Getenv(ENV_BASE_URL),
+	}
+	if cfg.Auth.APIKey == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", ENV_API_KEY)
+	}
+	if cfg.Auth.SecretAPIKey == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", ENV_SECRET_API_KEY)
+	}
+	return NewClient(cfg)
+}
@@ -0,0 +1,55 @@
+package porkbun
+
+import (
+	"context"
+	"net/http/httptrace"
+	"sync"
+)
+
+// ConnStats counts the connections requests of a client with
+// Config.TrackConnections were sent on. A high New count relative to Reused
+// means keep-alive is not working, e.g. because a proxy closes idle
+// connections, and every request pays for a TCP and TLS handshake.
+type ConnStats struct {
+	New    int
+	Reused int
+	// IdleReused counts the reused connections that had been idle in the
+	// pool, as opposed to handed over directly by a finished request.
+	IdleReused int
+}
+
+// connStats guards the counts behind ConnectionStats.
+type connStats struct {
+	mu    sync.Mutex
+	stats ConnStats
+}
+
+// ConnectionStats returns the connection counts over the client's lifetime.
+// They stay zero unless Config.TrackConnections is set.
+func (c *Client) ConnectionStats() ConnStats {
+	c.conns.mu.Lock()
+	defer c.conns.mu.Unlock()
+	return c.conns.stats
+}
+
+// withConnTrace adds a trace counting the connection a request gets to ctx
+// when Config.TrackConnections is set.
+func (c *Client) withConnTrace(ctx context.Context) context.Context {
+	if !c.config.TrackConnections {
+		return ctx
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			c.conns.mu.Lock()
+			defer c.conns.mu.Unlock()
+			if !info.Reused {
+				c.conns.stats.New++
+				return
+			}
+			c.conns.stats.Reused++
+			if info.WasIdle {
+				c.conns.stats.IdleReused++
+			}
+		},
+	})
+}
@@ -0,0 +1,41 @@
+package porkbun
+
+import "context"
+
+// postWrite is send for creates, edits and deletes, bounded by
+// Config.WriteTimeout once it starts and spaced by Config.WriteCooldown.
+// Each write reserves the earliest slot at least the cooldown after the
+// previous one, so concurrent writers queue up too, and the cooldown
+// restarts once the write has completed.
+func (c *Client) postWrite(ctx context.Context, url string, body []byte, out statusResponse, idempotent bool) error {
+	cooldown := c.config.WriteCooldown
+	if cooldown <= 0 {
+		return c.sendWrite(ctx, url, body, out, idempotent)
+	}
+	clock := c.clock()
+	c.writeMu.Lock()
+	start := clock.Now()
+	if c.nextWrite.After(start) {
+		start = c.nextWrite
+	}
+	c.nextWrite = start.Add(cooldown)
+	c.writeMu.Unlock()
+
+	if err := sleep(ctx, clock, start.Sub(clock.Now())); err != nil {
+		return err
+	}
+	defer func() {
+		c.writeMu.Lock()
+		if next := clock.Now().Add(cooldown); next.After(c.nextWrite) {
+			c.nextWrite = next
+		}
+		c.writeMu.Unlock()
+	}()
+	return c.sendWrite(ctx, url, body, out, idempotent)
+}
+
+func (c *Client) sendWrite(ctx context.Context, url string, body []byte, out statusResponse, idempotent bool) error {
+	ctx, cancel := withTimeout(ctx, c.config.WriteTimeout)
+	defer cancel()
+	return c.send(ctx, url, body, out, idempotent)
+}
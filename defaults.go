@@ -0,0 +1,37 @@
+package porkbun
+
+import "strings"
+
+// Targets of the records Porkbun creates when a domain is added: the parking
+// page at the apex and wildcard, and email forwarding.
+var (
+	porkbunParkingHosts = map[string]bool{
+		"pixie.porkbun.com": true,
+		"uixie.porkbun.com": true,
+	}
+	porkbunMailForwardHosts = map[string]bool{
+		"fwd1.porkbun.com": true,
+		"fwd2.porkbun.com": true,
+	}
+)
+
+const porkbunSPFInclude = "include:_spf.porkbun.com"
+
+// IsDefaultRecord reports whether the record looks like one of the records
+// Porkbun creates for a new domain: the ALIAS/CNAME parking records at the
+// apex and wildcard, and the MX and SPF records of email forwarding. The API
+// does not flag these records, so this is a heuristic on name and content;
+// reconcilers can use it to avoid deleting them.
+func (r *DNSRecord) IsDefaultRecord(domain string) bool {
+	name := nameKey(r.Name, domain)
+	content := strings.ToLower(strings.TrimSuffix(r.Content, "."))
+	switch strings.ToUpper(r.Type) {
+	case "ALIAS", "CNAME":
+		return (name == "" || name == "*") && porkbunParkingHosts[content]
+	case "MX":
+		return name == "" && porkbunMailForwardHosts[content]
+	case "TXT":
+		return name == "" && isMailSPF(content) && strings.Contains(content, porkbunSPFInclude)
+	}
+	return false
+}
@@ -0,0 +1,297 @@
+package porkbun
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RecordUpdate pairs a live record with the desired state it should be
+// edited to.
+type RecordUpdate struct {
+	Current *DNSRecord
+	Desired *DNSRecord
+}
+
+// RecordDiff lists the changes needed to turn a live zone into a desired
+// record set.
+type RecordDiff struct {
+	Create    []*DNSRecord
+	Update    []RecordUpdate
+	Delete    []*DNSRecord
+	Unchanged []*DNSRecord
+}
+
+// Empty reports whether the live zone already matches the desired set.
+func (d *RecordDiff) Empty() bool {
+	return len(d.Create) == 0 && len(d.Update) == 0 && len(d.Delete) == 0
+}
+
+// DiffOptions selects the fields DiffRecordsWith compares once records are
+// paired. Name, type and content always participate; TTL, Prio and Notes do
+// unless ignored here or left empty in the desired record.
+type DiffOptions struct {
+	// IgnoreTTL treats records differing only in TTL as unchanged, e.g. when
+	// syncing from a provider with other TTL conventions.
+	IgnoreTTL   bool
+	IgnorePrio  bool
+	IgnoreNotes bool
+}
+
+// DiffRecords compares the live records of domain with the desired ones.
+// Records are grouped by name and type; within a group, records with equal
+// content are paired first, and the remaining ones are paired in order as
+// updates, with leftovers created or deleted. A desired record leaves TTL,
+// Prio or Notes alone when the field is empty. Names may be relative or
+// fully qualified.
+func DiffRecords(domain string, current []*DNSRecord, desired []*DNSRecord) *RecordDiff {
+	return DiffRecordsWith(domain, current, desired, DiffOptions{})
+}
+
+// DiffRecordsWith is DiffRecords with control over the compared fields.
+func DiffRecordsWith(domain string, current []*DNSRecord, desired []*DNSRecord, opts DiffOptions) *RecordDiff {
+	diff := &RecordDiff{}
+	live := groupByNameType(domain, current)
+	want := groupByNameType(domain, desired)
+
+	keys := make([]string, 0, len(live)+len(want))
+	for key := range live {
+		keys = append(keys, key)
+	}
+	for key := range want {
+		if _, ok := live[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		have, need := live[key], want[key]
+		// Pair records whose content already matches.
+		var restHave, restNeed []*DNSRecord
+		used := make([]bool, len(have))
+		for _, d := range need {
+			matched := false
+			for i, h := range have {
+				if !used[i] && normalizeRecord(*h).Content == normalizeRecord(*d).Content {
+					used[i], matched = true, true
+					diff.pair(h, d, opts)
+					break
+				}
+			}
+			if !matched {
+				restNeed = append(restNeed, d)
+			}
+		}
+		for i, h := range have {
+			if !used[i] {
+				restHave = append(restHave, h)
+			}
+		}
+		// Turn what is left into edits where possible.
+		for len(restHave) > 0 && len(restNeed) > 0 {
+			diff.Update = append(diff.Update, RecordUpdate{Current: restHave[0], Desired: restNeed[0]})
+			restHave, restNeed = restHave[1:], restNeed[1:]
+		}
+		diff.Create = append(diff.Create, restNeed...)
+		diff.Delete = append(diff.Delete, restHave...)
+	}
+	return diff
+}
+
+// pair records a live record whose content matches the desired one as
+// unchanged or as an update of its other fields.
+func (d *RecordDiff) pair(current *DNSRecord, desired *DNSRecord, opts DiffOptions) {
+	if recordSatisfies(current, desired, opts) {
+		d.Unchanged = append(d.Unchanged, current)
+		return
+	}
+	d.Update = append(d.Update, RecordUpdate{Current: current, Desired: desired})
+}
+
+// recordSatisfies reports whether current needs no edit to match desired,
+// given matching name, type and content. A desired TTL of "0", like an
+// empty one, leaves the TTL to Porkbun and matches any.
+func recordSatisfies(current *DNSRecord, desired *DNSRecord, opts DiffOptions) bool {
+	return (opts.IgnoreTTL || desired.TTL == "" || desired.TTL == "0" || desired.TTL == current.TTL) &&
+		(opts.IgnorePrio || desired.Prio == "" || desired.Prio == current.Prio) &&
+		(opts.IgnoreNotes || desired.Notes == "" || desired.Notes == current.Notes)
+}
+
+func groupByNameType(domain string, records []*DNSRecord) map[string][]*DNSRecord {
+	groups := make(map[string][]*DNSRecord)
+	for _, record := range records {
+		key := nameKey(record.Name, domain) + " " + strings.ToUpper(record.Type)
+		groups[key] = append(groups[key], record)
+	}
+	return groups
+}
+
+// ChangeSet lists how a zone changed relative to an earlier snapshot.
+// Modified pairs a snapshot record (Current) with its new live state
+// (Desired).
+type ChangeSet struct {
+	Added    []*DNSRecord
+	Removed  []*DNSRecord
+	Modified []RecordUpdate
+}
+
+// Empty reports whether the zone is unchanged since the snapshot.
+func (s *ChangeSet) Empty() bool {
+	return len(s.Added) == 0 && len(s.Removed) == 0 && len(s.Modified) == 0
+}
+
+// RetrieveChanges retrieves the zone of domain and reports what changed
+// since snapshot, a previous result of RetrieveRecords, for polling-based
+// change detection. Records are matched by ID, so an edited record shows up
+// as modified; a record is modified when its name, type, content, TTL, prio
+// or notes differ.
+func (c *Client) RetrieveChanges(domain string, snapshot []*DNSRecord) (*ChangeSet, error) {
+	current, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	return compareSnapshot(domain, snapshot, current), nil
+}
+
+func compareSnapshot(domain string, snapshot []*DNSRecord, current []*DNSRecord) *ChangeSet {
+	changes := &ChangeSet{}
+	byID := make(map[string]*DNSRecord, len(snapshot))
+	for _, record := range snapshot {
+		byID[record.ID] = record
+	}
+	for _, record := range current {
+		old, ok := byID[record.ID]
+		if !ok {
+			changes.Added = append(changes.Added, record)
+			continue
+		}
+		delete(byID, record.ID)
+		if !sameRecord(domain, old, record) || old.Notes != record.Notes {
+			changes.Modified = append(changes.Modified, RecordUpdate{Current: old, Desired: record})
+		}
+	}
+	for _, record := range snapshot {
+		if _, ok := byID[record.ID]; ok {
+			changes.Removed = append(changes.Removed, record)
+		}
+	}
+	return changes
+}
+
+// ReconcileIDs maps the IDs of records in old to the IDs of the matching
+// records in new, two results of RetrieveRecords, so tools caching IDs can
+// follow records Porkbun recreated under a new ID. Records match on name,
+// type and content; identical records are paired in order. Records without
+// a counterpart are left out of the map.
+func ReconcileIDs(old []*DNSRecord, new []*DNSRecord) map[string]string {
+	key := func(record *DNSRecord) string {
+		r := normalizeRecord(*record)
+		return strings.ToLower(strings.TrimSuffix(r.Name, ".")) + " " + strings.ToUpper(r.Type) + " " + r.Content
+	}
+	available := make(map[string][]string)
+	for _, record := range new {
+		k := key(record)
+		available[k] = append(available[k], record.ID)
+	}
+	ids := make(map[string]string)
+	for _, record := range old {
+		k := key(record)
+		if candidates := available[k]; len(candidates) > 0 {
+			ids[record.ID] = candidates[0]
+			available[k] = candidates[1:]
+		}
+	}
+	return ids
+}
+
+// RetrieveRecordsWithChecksum returns the records of domain along with a
+// checksum of the record set. The checksum is independent of the order the
+// API lists records in and of formatting differences normalization removes,
+// so a poller can store it and only diff in detail once it changes.
+func (c *Client) RetrieveRecordsWithChecksum(domain string) ([]*DNSRecord, string, error) {
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, "", err
+	}
+	return records, recordsChecksum(records), nil
+}
+
+// recordsChecksum hashes the normalized, sorted form of records.
+func recordsChecksum(records []*DNSRecord) string {
+	normalized := make([]*DNSRecord, len(records))
+	for i, record := range records {
+		r := canonicalRecord(*record)
+		normalized[i] = &r
+	}
+	SortRecords(normalized)
+	h := sha256.New()
+	enc := json.NewEncoder(h)
+	for _, record := range normalized {
+		// Encoding a plain struct of strings cannot fail.
+		enc.Encode(record)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// FieldChange is a single field of a record that differs between two
+// states, named like its JSON key.
+type FieldChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// DiffFields lists the fields in which after differs from before, in the
+// order name, type, content, ttl, prio, notes.
+func DiffFields(before *DNSRecord, after *DNSRecord) []FieldChange {
+	var changes []FieldChange
+	for _, f := range []struct{ field, before, after string }{
+		{"name", before.Name, after.Name},
+		{"type", before.Type, after.Type},
+		{"content", before.Content, after.Content},
+		{"ttl", before.TTL, after.TTL},
+		{"prio", before.Prio, after.Prio},
+		{"notes", before.Notes, after.Notes},
+	} {
+		if f.before != f.after {
+			changes = append(changes, FieldChange{Field: f.field, Before: f.before, After: f.after})
+		}
+	}
+	return changes
+}
+
+// EditResult is the outcome of EditRecordWithDiff: the record as retrieved
+// before and after the edit and the fields that changed between the two.
+type EditResult struct {
+	Before  *DNSRecord
+	After   *DNSRecord
+	Changes []FieldChange
+}
+
+// EditRecordWithDiff is EditRecord that retrieves the record before and
+// after the edit and reports what actually changed server-side, which may
+// differ from what was sent when Porkbun normalizes or ignores part of an
+// edit, e.g. by raising a TTL to its minimum. It costs two extra retrieves.
+// When only the retrieve after the edit fails, the edit did take effect;
+// the result then carries Before alone next to the error.
+func (c *Client) EditRecordWithDiff(domain string, id string, record *DNSRecord) (*EditResult, error) {
+	before, err := c.RetrieveRecord(domain, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.EditRecord(domain, id, record); err != nil {
+		return nil, err
+	}
+	result := &EditResult{Before: before}
+	after, err := c.RetrieveRecord(domain, id)
+	if err != nil {
+		return result, fmt.Errorf("retrieving edited record %s: %w", id, err)
+	}
+	result.After = after
+	result.Changes = DiffFields(before, after)
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package porkbun
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestZeroTTLMeansServerDefault(t *testing.T) {
+	current := []*DNSRecord{{ID: "1", Name: "www.example.com", Type: "A", Content: "1.1.1.1", TTL: "600"}}
+	for _, ttl := range []string{"", "0"} {
+		desired := []*DNSRecord{{Name: "www", Type: "A", Content: "1.1.1.1", TTL: ttl}}
+		if diff := DiffRecords("example.com", current, desired); !diff.Empty() {
+			t.Errorf("TTL %q: got %+v, want no changes", ttl, diff)
+		}
+	}
+	desired := []*DNSRecord{{Name: "www", Type: "A", Content: "1.1.1.1", TTL: "3600"}}
+	if diff := DiffRecords("example.com", current, desired); len(diff.Update) != 1 {
+		t.Errorf("TTL 3600: got %+v, want one update", diff)
+	}
+}
+
+func TestZeroTTLIsOmitted(t *testing.T) {
+	ts, c := newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS","id":1}`))
+	for _, ttl := range []string{"", "0"} {
+		if _, err := c.CreateRecord("example.com", &DNSRecord{Type: "A", Content: "1.1.1.1", TTL: ttl}); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := ts.last(t).field(t, "ttl"); ok {
+			t.Errorf("TTL %q: create body %s has a ttl", ttl, ts.last(t).Body)
+		}
+	}
+}
+
+func TestSetZoneTTLSkipsMatching(t *testing.T) {
+	ts, c := newTestServer(t, nil, respond(http.StatusOK, retrievePayload))
+	for _, ttl := range []int{0, -1} {
+		if _, err := c.SetZoneTTL("example.com", ttl); err == nil {
+			t.Errorf("SetZoneTTL(%d): got no error", ttl)
+		}
+	}
+	if n := len(ts.Requests()); n != 0 {
+		t.Errorf("%d requests for an invalid ttl", n)
+	}
+	results, err := c.SetZoneTTL("example.com", PORKBUN_MIN_TTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].ID != "106926660" || results[0].Record.TTL != "600" {
+		t.Errorf("got %+v, want only the 3600s record set to 600", results)
+	}
+	if n := len(ts.Requests()); n != 2 {
+		t.Errorf("%d requests, want a retrieve and one edit", n)
+	}
+}
+
+func TestDiffRecordsMixedCase(t *testing.T) {
+	current := []*DNSRecord{
+		{ID: "1", Name: "WWW.Example.COM", Type: "A", Content: "1.1.1.1", TTL: "600"},
+		{ID: "2", Name: "Example.com", Type: "MX", Content: "mx.example.com", TTL: "600", Prio: "10"},
+	}
+	desired := []*DNSRecord{
+		{Name: "www.", Type: "a", Content: "1.1.1.1"},
+		{Name: "@", Type: "MX", Content: "mx.example.com.", Prio: "10"},
+	}
+	if diff := DiffRecords("example.com", current, desired); !diff.Empty() {
+		t.Errorf("got %+v, want no changes", diff)
+	}
+}
+
+func TestApplyZoneZeroTTLKeepsLiveTTL(t *testing.T) {
+	for _, tt := range []struct {
+		desiredTTL string
+		compare    DiffOptions
+	}{
+		{"0", DiffOptions{}},
+		{"300", DiffOptions{IgnoreTTL: true}},
+	} {
+		zone := newFakeZone("example.com",
+			&DNSRecord{Name: "www", Type: "A", Content: "192.0.2.1", TTL: "3600", Notes: "old"},
+		)
+		s, c := newTestServer(t, nil, zone.ServeHTTP)
+		desired := []*DNSRecord{{Name: "www", Type: "A", Content: "192.0.2.1", TTL: tt.desiredTTL, Notes: "new"}}
+		result, err := c.ApplyZone(context.Background(), "example.com", desired, ApplyOptions{Compare: tt.compare})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Diff.Update) != 1 || len(result.Results) != 1 || result.Results[0].Err != nil {
+			t.Fatalf("TTL %q: result = %+v, want one successful edit of the notes", tt.desiredTTL, result)
+		}
+		if ttl, _ := s.last(t).field(t, "ttl"); ttl != "3600" {
+			t.Errorf("TTL %q: edit sent ttl %v, want the live 3600", tt.desiredTTL, ttl)
+		}
+		if records := zone.Records(); records[0].TTL != "3600" || records[0].Notes != "new" {
+			t.Errorf("TTL %q: zone = %+v", tt.desiredTTL, records[0])
+		}
+	}
+}
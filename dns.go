@@ -2,11 +2,18 @@ package porkbun
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
 	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 )
 
 const PORKBUN_DNS_BASE = PORKBUN_API_BASE + "/dns"
@@ -14,15 +21,213 @@ const PORKBUN_DNS_CREATE = PORKBUN_DNS_BASE + "/create/%s"
 const PORKBUN_DNS_EDIT = PORKBUN_DNS_BASE + "/edit/%s/%s"
 const PORKBUN_DNS_DELETE = PORKBUN_DNS_BASE + "/delete/%s/%s"
 const PORKBUN_DNS_RETRIEVE = PORKBUN_DNS_BASE + "/retrieve/%s"
+const PORKBUN_DNS_RETRIEVE_ID = PORKBUN_DNS_BASE + "/retrieve/%s/%s"
+const PORKBUN_DNS_RETRIEVE_NAME_TYPE = PORKBUN_DNS_BASE + "/retrieveByNameType/%s/%s/%s"
 const STATUS_SUCCESS = "SUCCESS"
 
+// DEFAULT_MAX_RESPONSE_BYTES is the default for Config.MaxResponseBytes. It
+// comfortably fits the largest zones while bounding what a misbehaving
+// upstream can make the client buffer.
+const DEFAULT_MAX_RESPONSE_BYTES = 10 << 20
+
+// DNSClient is the core set of operations implemented by *Client, for code
+// that wants to accept a test double instead of a real client: one method
+// per API endpoint the package wraps, plus the record helpers most callers
+// build on. Bulk, reporting and other conveniences composed from these are
+// left out so that doubles stay small; a method wrapping a new endpoint
+// belongs here too.
+type DNSClient interface {
+	Ping() (string, error)
+	ListAllDomains(ctx context.Context) ([]*Domain, error)
+	GetNameservers(domain string) ([]string, error)
+
+	CreateRecord(domain string, dnsrecord *DNSRecord) (string, error)
+	EditRecord(domain string, id string, dnsrecord *DNSRecord) error
+	DeleteRecord(domain string, id string) error
+	RetrieveRecords(domain string) ([]*DNSRecord, error)
+	RetrieveRecord(domain string, id string) (*DNSRecord, error)
+	RetrieveRecordsByNameType(domain string, recordType string, subdomain string) ([]*DNSRecord, error)
+	RetrieveRecordsByID(domain string) (map[string]*DNSRecord, error)
+
+	UpsertRecord(domain string, record *DNSRecord) (string, error)
+	DeleteRecordIfExists(domain string, id string) (bool, error)
+	GetRecordContent(domain string, recordType string, subdomain string) (string, error)
+	SetRecordNotes(domain string, id string, notes string) error
+	EditRecordIfUnchanged(domain string, id string, expected *DNSRecord, desired *DNSRecord) error
+	ApplyZone(ctx context.Context, domain string, desired []*DNSRecord, opts ApplyOptions) (*ApplyResult, error)
+
+	GetDNSSECRecords(domain string) ([]*DSRecord, error)
+	GetURLForwards(domain string) ([]*URLForward, error)
+	AddURLForward(domain string, forward *URLForward) error
+	DeleteURLForward(domain string, id string) error
+	RetrieveSSLBundle(domain string) (*SSLBundle, error)
+}
+
+var _ DNSClient = (*Client)(nil)
+
+// Client talks to the Porkbun API. A Client is safe for concurrent use by
+// multiple goroutines; its mutable state, the credentials and what it
+// records about past responses, is guarded by mutexes.
 type Client struct {
 	config Config
+
+	// mu guards config.Auth and its marshaled form, which SetAuth may
+	// replace at any time.
+	mu       sync.RWMutex
+	authJson []byte
+
+	// statsMu guards what the client records about the last response.
+	statsMu    sync.Mutex
+	quota      Quota
+	lastErr    error
+	lastStatus int
+
+	// writeMu guards the earliest time the next write may start under
+	// Config.WriteCooldown.
+	writeMu   sync.Mutex
+	nextWrite time.Time
+
+	// tracePath is the ENV_DEBUG file, read once by NewClient; traceMu
+	// keeps concurrent entries from interleaving.
+	tracePath string
+	traceMu   sync.Mutex
+
+	// retryBudget is shared by all calls; nil when Config.RetryBudget is 0.
+	retryBudget *retryBudget
+
+	// limiter paces every request; nil when Config.RequestsPerSecond is 0.
+	limiter *rateLimiter
+
+	// cacheMu guards the bodies kept for conditional reads, by URL.
+	cacheMu sync.Mutex
+	cache   map[string]cachedResponse
+
+	// seenMu guards the record history kept for Config.TrackRecordHistory.
+	seenMu sync.Mutex
+	seen   map[string]*seenRecord
+
+	// conns counts connections for Config.TrackConnections.
+	conns connStats
 }
 
 type Config struct {
 	Auth   Auth
 	Client *http.Client
+
+	// BaseURL, when set, replaces PORKBUN_API_BASE in every request URL,
+	// e.g. to point the client at a proxy or a test server.
+	BaseURL string
+	// Timeout bounds each request when Client is nil. Zero means no timeout.
+	Timeout time.Duration
+	// Transport, when set and Client is nil, is used as the base round
+	// tripper of the package's client, so middleware such as otelhttp can be
+	// layered in while keeping Timeout and the User-Agent. The tuning fields
+	// below only apply to the default transport and are ignored.
+	Transport http.RoundTripper
+	// ReadTimeout and WriteTimeout bound each retrieve, respectively each
+	// create, edit and delete, including its retries, when the call's
+	// context has no deadline of its own; the methods without a context
+	// argument never do. Unlike Timeout they also apply with a custom
+	// Client. Zero means no bound.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// ContentType replaces PORKBUN_CONTENT_TYPE as the Content-Type header
+	// of every request, e.g. "application/json; charset=utf-8" for proxies
+	// that insist on a charset.
+	ContentType string
+	// AuditHook, when set, is called after every successful create, edit and
+	// delete. For edits and deletes the record is retrieved beforehand so
+	// the event can carry its previous state, costing one extra API call.
+	AuditHook func(AuditEvent)
+	// MaxResponseBytes caps the size of a response body the client will
+	// decode. Zero means DEFAULT_MAX_RESPONSE_BYTES.
+	MaxResponseBytes int64
+	// MaxRecordsPerZone, when positive, makes CreateRecord refuse with
+	// ErrZoneFull once the zone holds that many records, as a safety valve
+	// against runaway automation. Each create then costs one extra retrieve.
+	MaxRecordsPerZone int
+	// MaxRetries is how often a call failing in a way RetryPolicy deems
+	// transient is retried, with jittered exponential backoff; creates and
+	// edits only as RetryNonIdempotent allows. Zero disables retries.
+	MaxRetries int
+	// RetryPolicy decides whether a failed call is retried, given its
+	// response, nil when none arrived, and error. Nil means
+	// DefaultRetryPolicy. It is not consulted once the context is done.
+	RetryPolicy func(*http.Response, error) bool
+	// RetryNonIdempotent lets creates and edits be retried like reads and
+	// deletes, here and in ApplyRecordsPaced. By default they are only
+	// retried after a rate-limit rejection or a maintenance page, since a
+	// create Porkbun processed before the failure was noticed would be made
+	// twice. Opt in when duplicates are harmless or checked for.
+	RetryNonIdempotent bool
+	// RequestsPerSecond, when positive, paces every request, retries
+	// included, to that rate across all goroutines sharing the client, so
+	// bursts stay under Porkbun's rate limit instead of hitting it. A call
+	// whose context is done while waiting returns promptly with an error
+	// wrapping the context's error.
+	RequestsPerSecond float64
+	// RetryBudget bounds the retries of all goroutines sharing the client to
+	// that many per minute, so an outage does not multiply the load on
+	// Porkbun. Zero means no shared bound.
+	RetryBudget int
+	// TrackRecordHistory makes the client remember when it first saw each
+	// record and when its fields last changed, see RecordHistory. The
+	// history lives in memory for the lifetime of the client.
+	TrackRecordHistory bool
+	// TrackConnections makes the client count new and reused connections,
+	// see ConnectionStats. It is off by default as it adds a trace hook to
+	// every request.
+	TrackConnections bool
+	// Redactor, when set, is applied to response bodies and messages before
+	// they are embedded in errors or logs. The default replaces the API key
+	// and secret with REDACTED; organizations that want domains or other
+	// details masked too can supply their own.
+	Redactor func(string) string
+	// WriteCooldown, when positive, is an opt-in pause after every create,
+	// edit and delete before the next one may start, letting Porkbun settle
+	// between back-to-back writes. Reads are not delayed.
+	WriteCooldown time.Duration
+	// SafeEdit makes EditRecord retrieve the record first and keep its value
+	// for every field left empty, since an edit otherwise replaces the whole
+	// record and a forgotten Type or Content blanks it. An empty Name then
+	// keeps the record's name rather than moving it to the apex; pass the
+	// domain itself as Name for that. Each edit costs one extra retrieve.
+	SafeEdit bool
+	// MultipleMatch picks the record single-result helpers such as
+	// GetRecordContent and UpsertRecord act on when several share the name
+	// and type. Nil means ErrorOnMultiple; FirstMatch and SelectFirstWhere
+	// are the alternatives provided.
+	MultipleMatch MatchSelector
+	// Clock replaces the real clock behind retries, waits, pacing and
+	// recorded timestamps, e.g. with a fake in tests. Nil means real time.
+	Clock Clock
+	// DefaultRecordType is the type NewRecord gives records, such as "AAAA".
+	// Empty means DEFAULT_RECORD_TYPE.
+	DefaultRecordType string
+	// PreserveNameCase sends record names on create and edit as given. By
+	// default they are lowercased, since DNS names are case-insensitive and
+	// "WWW" and "www" would otherwise look like distinct records; content is
+	// never changed.
+	PreserveNameCase bool
+	// AllowedTypes, when set, limits create, edit and delete to records of
+	// these types, such as []string{"TXT"} for an ACME client; anything else
+	// fails with ErrTypeNotAllowed before the write is sent. Edits and deletes
+	// retrieve the record first to learn its current type.
+	AllowedTypes []string
+	// Resolver looks up hostnames on the client's behalf, such as the
+	// target SetApexALIAS checks before pointing the apex at it. Nil means
+	// net.DefaultResolver.
+	Resolver *net.Resolver
+
+	// Transport tuning, applied only when Client is nil. Zero values keep
+	// the defaults of Go's http.DefaultTransport.
+	//
+	// MaxIdleConnsPerHost raises the number of keep-alive connections kept
+	// per host, which avoids connection churn for highly concurrent callers.
+	MaxIdleConnsPerHost int
+	// DisableHTTP2 turns off ForceAttemptHTTP2 and restricts the transport
+	// to HTTP/1.1.
+	DisableHTTP2 bool
 }
 
 type Auth struct {
@@ -30,6 +235,17 @@ type Auth struct {
 	SecretAPIKey string `json:"secretapikey,omitempty"`
 }
 
+// DNSRecord mirrors a record as returned by the retrieve endpoint, e.g.
+//
+//	{"id":"106926659","name":"www.example.com","type":"A",
+//	 "content":"1.1.1.1","ttl":"600","prio":"0","notes":""}
+//
+// These are all the fields Porkbun returns; there is no proxy flag or other
+// metadata, so a retrieved record can be passed back to EditRecord without
+// losing information. Retrieved names are fully qualified while create and
+// edit expect the subdomain; the domain suffix is stripped from Name before
+// a record is sent, so both forms are accepted. TTL is in seconds, and an
+// empty or "0" TTL leaves it to Porkbun's default.
 type DNSRecord struct {
 	ID      string `json:"id,omitempty"`
 	Name    string `json:"name,omitempty"`
@@ -40,15 +256,134 @@ type DNSRecord struct {
 	Notes   string `json:"notes,omitempty"`
 }
 
+// DNSResponse is the body returned by the retrieve, edit and delete
+// endpoints. Records is only populated by retrieve.
 type DNSResponse struct {
 	Status  string       `json:"status,omitempty"`
+	Message string       `json:"message,omitempty"`
 	Id      json.Number  `json:"id,omitempty"`
 	Records []*DNSRecord `json:"records,omitempty"`
+	// Extra holds any other top-level fields, see CreateResponse.Extra.
+	Extra map[string]json.RawMessage `json:"-"`
+	// Accepted is set when the API answered 202 Accepted, see
+	// CreateResponse.Accepted.
+	Accepted bool `json:"-"`
 }
 
-type dnsRecordWithAuth struct {
-	Auth
-	DNSRecord
+func (r *DNSResponse) setAccepted() { r.Accepted = true }
+
+func (r *DNSResponse) UnmarshalJSON(data []byte) error {
+	type plain DNSResponse
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	r.Extra = extraFields(data, "status", "message", "id", "records")
+	return nil
+}
+
+// String renders the response for logs and CLI output: the status, the
+// message and ID when present, then one aligned line per record, e.g.
+//
+//	SUCCESS, 2 records
+//	106926659  www.example.com  A   1.1.1.1         ttl 600
+//	106926660  example.com      MX  mx.example.com  ttl 600 prio 10
+func (r *DNSResponse) String() string {
+	var b strings.Builder
+	b.WriteString(r.Status)
+	if r.Message != "" {
+		b.WriteString(": " + r.Message)
+	}
+	if r.Id != "" {
+		b.WriteString(", id " + r.Id.String())
+	}
+	if r.Records != nil {
+		fmt.Fprintf(&b, ", %d records", len(r.Records))
+	}
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	for _, record := range r.Records {
+		fmt.Fprintf(tw, "\n%s\t%s\t%s\t%s\t", record.ID, record.Name, record.Type, record.Content)
+		if record.TTL != "" {
+			fmt.Fprintf(tw, "ttl %s", record.TTL)
+		}
+		if record.Prio != "" {
+			fmt.Fprintf(tw, " prio %s", record.Prio)
+		}
+	}
+	tw.Flush()
+	return b.String()
+}
+
+// CreateResponse is the body returned by the create endpoint, which only
+// carries the id of the new record.
+type CreateResponse struct {
+	Status  string      `json:"status,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Id      json.Number `json:"id,omitempty"`
+	// Extra holds the top-level fields the response carried besides the
+	// ones above, such as a secondary state telling a queued change from an
+	// applied one, so they are not lost once the status is checked.
+	Extra map[string]json.RawMessage `json:"-"`
+	// Accepted is set when the API answered 202 Accepted: the change was
+	// queued and may not be visible yet. Client.LastStatus reports the same
+	// for methods that do not return the response.
+	Accepted bool `json:"-"`
+}
+
+func (r *CreateResponse) setAccepted() { r.Accepted = true }
+
+func (r *CreateResponse) UnmarshalJSON(data []byte) error {
+	type plain CreateResponse
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	r.Extra = extraFields(data, "status", "message", "id")
+	return nil
+}
+
+// extraFields returns the top-level fields of the JSON object data other
+// than known, or nil when there are none.
+func extraFields(data []byte, known ...string) map[string]json.RawMessage {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return nil
+	}
+	for _, key := range known {
+		delete(fields, key)
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return fields
+}
+
+// statusResponse is implemented by every response body carrying a status
+// and, on failure, a message.
+type statusResponse interface {
+	status() (string, string)
+}
+
+// pendingResponse is implemented by responses of endpoints that may report
+// work still in progress. Such a status is handed to the caller instead of
+// being treated as a failure.
+type pendingResponse interface {
+	pending(status string, message string) bool
+}
+
+// validatedResponse is implemented by responses whose success carries a
+// payload that must be present, such as the id of a created record. A
+// SUCCESS status without it is reported as ErrMalformedResponse.
+type validatedResponse interface {
+	validate() error
+}
+
+func (r *DNSResponse) status() (string, string)    { return r.Status, r.Message }
+func (r *CreateResponse) status() (string, string) { return r.Status, r.Message }
+
+func (r *CreateResponse) validate() error {
+	if r.Id.String() == "" {
+		return fmt.Errorf("create returned no record id")
+	}
+	return nil
 }
 
 func NewClient(cfg *Config) (*Client, error) {
@@ -58,147 +393,607 @@ func NewClient(cfg *Config) (*Client, error) {
 	if cfg.Auth.SecretAPIKey == "" {
 		return nil, fmt.Errorf("SecretAPIKey should not be empty")
 	}
-	if cfg.Client == nil {
-		cfg.Client = http.DefaultClient
+	// Work on a copy so the caller's Config is never written to, which would
+	// race if it were shared between goroutines.
+	config := *cfg
+	if config.Client == nil {
+		config.Client = newHTTPClient(&config)
+	}
+	c := &Client{config: config, tracePath: os.Getenv(ENV_DEBUG)}
+	if config.RetryBudget > 0 {
+		c.retryBudget = newRetryBudget(config.RetryBudget, c.clock())
+	}
+	if config.RequestsPerSecond > 0 {
+		c.limiter = newRateLimiter(config.RequestsPerSecond, c.clock())
+	}
+	if err := c.SetAuth(config.Auth); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// SetAuth replaces the credentials used for subsequent requests, keeping the
+// client's connection pool and other state. Requests already in flight
+// complete with the old credentials.
+func (c *Client) SetAuth(auth Auth) error {
+	if auth.APIKey == "" {
+		return fmt.Errorf("APIKey should not be empty")
 	}
-	return &Client{config: *cfg}, nil
+	if auth.SecretAPIKey == "" {
+		return fmt.Errorf("SecretAPIKey should not be empty")
+	}
+	authJson, err := json.Marshal(auth)
+	if err != nil {
+		return fmt.Errorf("creating json: %w", err)
+	}
+	c.mu.Lock()
+	c.config.Auth = auth
+	c.authJson = authJson
+	c.mu.Unlock()
+	return nil
 }
 
+func (c *Client) auth() Auth {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.config.Auth
+}
+
+// getAuthJson returns the credentials marshaled once by SetAuth. The slice
+// is shared and must not be modified.
 func (c *Client) getAuthJson() ([]byte, error) {
-	json, err := json.Marshal(c.config.Auth)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.authJson, nil
+}
+
+// getDNSRecordWithAuthJson builds the create and edit body in the shape
+// documented by Porkbun:
+//
+//	{"apikey":"...","secretapikey":"...","name":"www","type":"A",
+//	 "content":"1.1.1.1","ttl":"600","prio":"10","notes":"..."}
+//
+// The record id is only ever part of the edit URL, never of the body.
+func (c *Client) getDNSRecordWithAuthJson(domain string, dnsRecord *DNSRecord) ([]byte, error) {
+	return c.getPayloadWithAuthJson(c.recordPayload(domain, dnsRecord))
+}
+
+// recordPayload returns the record as it is sent in a create or edit body.
+func (c *Client) recordPayload(domain string, dnsRecord *DNSRecord) DNSRecord {
+	record := normalizeRecord(*dnsRecord)
+	record.ID = ""
+	record.Name = relativeName(record.Name, domain)
+	if !c.config.PreserveNameCase {
+		record.Name = strings.ToLower(record.Name)
+	}
+	return record
+}
+
+// getPayloadWithAuthJson marshals payload, which must encode to a JSON
+// object, and splices the cached credentials into it.
+func (c *Client) getPayloadWithAuthJson(payload interface{}) ([]byte, error) {
+	authJson, _ := c.getAuthJson()
+	if payload == nil {
+		return authJson, nil
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("creating json: %w", err)
+	}
+	if bytes.Equal(raw, []byte("null")) {
+		return authJson, nil
+	}
+	if len(raw) < 2 || raw[0] != '{' {
+		return nil, fmt.Errorf("payload must marshal to a JSON object, got %s", snippet(c.redact(string(raw))))
+	}
+	if len(raw) == 2 {
+		return authJson, nil
+	}
+	body := make([]byte, 0, len(authJson)+len(raw))
+	body = append(body, authJson[:len(authJson)-1]...)
+	body = append(body, ',')
+	return append(body, raw[1:]...), nil
+}
+
+// requestURL points url at Config.BaseURL when one is set.
+func (c *Client) requestURL(url string) string {
+	if c.config.BaseURL != "" && strings.HasPrefix(url, PORKBUN_API_BASE) {
+		return strings.TrimSuffix(c.config.BaseURL, "/") + strings.TrimPrefix(url, PORKBUN_API_BASE)
+	}
+	return url
+}
+
+func (c *Client) do(ctx context.Context, url string, body []byte) (*http.Response, error) {
+	key := url
+	url = c.requestURL(url)
+	// A bytes.Reader body makes the request carry a Content-Length instead
+	// of using chunked encoding, which some strict proxies reject.
+	req, err := http.NewRequestWithContext(c.withConnTrace(ctx), PORKBUN_HTTP_METHOD, url, bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("Error creating json")
+		return nil, err
 	}
-	return json, nil
+	req.Header.Set("Content-Type", c.contentType())
+	req.Header.Set("User-Agent", PORKBUN_USER_AGENT)
+	c.setConditionalHeaders(ctx, key, req)
+	res, err := c.httpClient(ctx).Do(req)
+	if err == nil {
+		c.recordQuota(res)
+	}
+	c.trace(url, body, res, err)
+	return res, err
 }
 
-func (c *Client) getDNSRecordWithAuthJson(dnsRecord *DNSRecord) ([]byte, error) {
-	lee := dnsRecordWithAuth{
-		Auth:      c.config.Auth,
-		DNSRecord: *dnsRecord,
+// contentType returns Config.ContentType, or PORKBUN_CONTENT_TYPE when unset.
+func (c *Client) contentType() string {
+	if c.config.ContentType != "" {
+		return c.config.ContentType
 	}
-	json, err := json.Marshal(lee)
+	return PORKBUN_CONTENT_TYPE
+}
+
+// Do sends an authenticated request to url and returns the raw response,
+// for callers that need headers or fields the typed methods discard.
+// payload is marshaled to a JSON object and the credentials are added to
+// it; it may be nil. The response is returned without checking the HTTP or
+// API status and the caller must close its body.
+func (c *Client) Do(ctx context.Context, url string, payload interface{}) (*http.Response, error) {
+	body, err := c.getPayloadWithAuthJson(payload)
 	if err != nil {
-		return nil, fmt.Errorf("Error creating json")
+		return nil, err
 	}
-	return json, nil
+	return c.do(ctx, url, body)
 }
 
 // Helper land
-func requireSuccess(dnsRes *DNSResponse) error {
-	if !strings.EqualFold(dnsRes.Status, STATUS_SUCCESS) {
-		return fmt.Errorf("Expected `success` code, got %s", dnsRes.Status)
+
+// endpoint fills the path segments into format, escaping each of them so
+// names with special characters still produce a valid URL. Internationalized
+// names are converted to punycode first.
+func endpoint(format string, segments ...string) string {
+	args := make([]interface{}, len(segments))
+	for i, segment := range segments {
+		args[i] = url.PathEscape(asciiName(segment))
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func requireDomain(domain string) error {
+	if domain == "" {
+		return fmt.Errorf("domain must not be empty")
 	}
 	return nil
 }
 
-func requireOK(res *http.Response, err error) (*http.Response, error) {
+func requireDomainAndID(domain string, id string) error {
+	if err := requireDomain(domain); err != nil {
+		return err
+	}
+	if id == "" {
+		return fmt.Errorf("id must not be empty")
+	}
+	return nil
+}
+
+// postOnce sends body to url once and interprets the response into out.
+// The response is returned, with its body closed, for the retry policy; it
+// is nil when no response arrived.
+func (c *Client) postOnce(ctx context.Context, url string, body []byte, out statusResponse) (*http.Response, error) {
+	res, err := c.do(ctx, url, body)
 	if err != nil {
-		if res != nil {
-			res.Body.Close()
-		}
+		err = &sendError{err: err}
+		c.recordResult(0, err)
 		return nil, err
 	}
-	if res.StatusCode != 200 {
-		return nil, generateUnexpectedResponseCodeError(res)
+	defer drainAndClose(res.Body)
+	res, keep := c.conditionalResponse(ctx, url, res)
+	err = c.interpretResponse(res, out)
+	if err == nil && keep != nil {
+		keep()
 	}
-	return res, nil
+	c.recordResult(res.StatusCode, err)
+	return res, err
 }
 
-func generateUnexpectedResponseCodeError(resp *http.Response) error {
-	var buf bytes.Buffer
-	io.Copy(&buf, resp.Body)
-	resp.Body.Close()
-	return fmt.Errorf("Unexpected response code: %d (%s)", resp.StatusCode, buf.Bytes())
+// drainAndClose discards what is left of a response body before closing it
+// so the connection can go back to the pool, including after a decode error
+// or a cancelled context. Bodies far larger than any API response are not
+// worth draining; the connection is dropped instead.
+func drainAndClose(body io.ReadCloser) {
+	io.Copy(io.Discard, io.LimitReader(body, 64<<10))
+	body.Close()
 }
 
-func extractDNSResponse(res *http.Response, err error) (*DNSResponse, error) {
+// interpretResponse is the single place deciding whether a call succeeded.
+// Porkbun reports some failures as HTTP 200 with status ERROR in the body
+// and others as an error code carrying the same JSON body, so both are
+// checked and turned into one *APIError. A body without a status, such as a
+// truncated one, or a success missing the payload its endpoint promises
+// (see validatedResponse), is reported as ErrMalformedResponse. Body text in
+// the errors goes through the client's redactor.
+func (c *Client) interpretResponse(res *http.Response, out statusResponse) error {
+	data, err := io.ReadAll(&maxBytesReader{r: res.Body, remaining: c.maxResponseBytes()})
 	if err != nil {
-		return &DNSResponse{}, err
+		return fmt.Errorf("reading response: %w", err)
 	}
-	var dnsResp DNSResponse
-	if err := json.NewDecoder(res.Body).Decode(&dnsResp); err != nil {
-		return &DNSResponse{}, err
+	if isMaintenancePage(res, data) {
+		return fmt.Errorf("%w (HTTP %d): %s", ErrServiceMaintenance, res.StatusCode, snippet(c.redact(string(data))))
 	}
-	if err := requireSuccess(&dnsResp); err != nil {
-		return &DNSResponse{}, err
+	if !isSuccessStatus(res.StatusCode) {
+		return newAPIError(res.StatusCode, data, c.redact)
 	}
-	return &dnsResp, nil
+	if len(bytes.TrimSpace(data)) == 0 {
+		return fmt.Errorf("%w: empty body", ErrMalformedResponse)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("decoding response: %w", err)
+	}
+	status, message := out.status()
+	if status == "" {
+		return fmt.Errorf("%w: no status in %s", ErrMalformedResponse, snippet(c.redact(string(data))))
+	}
+	if p, ok := out.(pendingResponse); ok && p.pending(status, message) {
+		return nil
+	}
+	if !strings.EqualFold(status, STATUS_SUCCESS) {
+		return &APIError{
+			StatusCode: res.StatusCode,
+			Status:     status,
+			Message:    c.redact(message),
+			Err:        classifyMessage(message),
+		}
+	}
+	if v, ok := out.(validatedResponse); ok {
+		if err := v.validate(); err != nil {
+			return fmt.Errorf("%w: %v", ErrMalformedResponse, err)
+		}
+	}
+	if a, ok := out.(acceptedResponse); ok && res.StatusCode == http.StatusAccepted {
+		a.setAccepted()
+	}
+	return nil
+}
+
+// isSuccessStatus reports whether an HTTP status may carry a successful API
+// response: 200, and 201 or 202 for a change accepted for asynchronous
+// processing. The body still decides the outcome.
+func isSuccessStatus(code int) bool {
+	switch code {
+	case http.StatusOK, http.StatusCreated, http.StatusAccepted:
+		return true
+	}
+	return false
+}
+
+// acceptedResponse is implemented by responses that report when Porkbun
+// answered 202 Accepted, i.e. queued the change rather than applying it.
+type acceptedResponse interface {
+	setAccepted()
+}
+
+// isMaintenancePage reports whether a successful or 503 response is an HTML
+// page rather than JSON, which Porkbun serves during maintenance.
+func isMaintenancePage(res *http.Response, data []byte) bool {
+	if !isSuccessStatus(res.StatusCode) && res.StatusCode != http.StatusServiceUnavailable {
+		return false
+	}
+	trimmed := bytes.TrimSpace(data)
+	return strings.Contains(strings.ToLower(res.Header.Get("Content-Type")), "text/html") ||
+		(len(trimmed) > 0 && trimmed[0] == '<')
+}
+
+func (c *Client) maxResponseBytes() int64 {
+	if c.config.MaxResponseBytes > 0 {
+		return c.config.MaxResponseBytes
+	}
+	return DEFAULT_MAX_RESPONSE_BYTES
+}
+
+// maxBytesReader fails with ErrResponseTooLarge once more than remaining
+// bytes have been read, instead of silently truncating like io.LimitReader.
+type maxBytesReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (l *maxBytesReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrResponseTooLarge
+	}
+	return n, err
 }
 
 // Main function land
 func (c *Client) CreateRecord(domain string, dnsrecord *DNSRecord) (string, error) {
-	authjson, err := c.getDNSRecordWithAuthJson(dnsrecord)
-	if err != nil {
+	return c.createRecord(context.Background(), domain, dnsrecord)
+}
+
+func (c *Client) createRecord(ctx context.Context, domain string, dnsrecord *DNSRecord) (string, error) {
+	if err := requireDomain(domain); err != nil {
+		return "", err
+	}
+	if err := c.checkType(dnsrecord.Type); err != nil {
 		return "", err
 	}
-	res, err := requireOK(
-		c.config.Client.Post(
-			fmt.Sprintf(PORKBUN_DNS_CREATE, domain),
-			"application/json",
-			bytes.NewBuffer(authjson)),
-	)
+	if err := c.checkZoneSize(ctx, domain); err != nil {
+		return "", err
+	}
+	authjson, err := c.getDNSRecordWithAuthJson(domain, dnsrecord)
 	if err != nil {
 		return "", err
 	}
-	defer res.Body.Close()
-	d, e := extractDNSResponse(res, err)
-	return d.Id.String(), e
+	var d CreateResponse
+	if err := c.postWrite(ctx, endpoint(PORKBUN_DNS_CREATE, domain), authjson, &d, false); err != nil {
+		return "", err
+	}
+	c.audit(AUDIT_OP_CREATE, domain, d.Id.String(), nil, dnsrecord)
+	c.observeCreate(domain, d.Id.String())
+	return d.Id.String(), nil
 }
 
-func (c *Client) EditRecord(domain string, id string, dnsrecord *DNSRecord) error {
-	authjson, err := c.getDNSRecordWithAuthJson(dnsrecord)
+// checkType enforces Config.AllowedTypes, comparing types
+// case-insensitively.
+func (c *Client) checkType(typ string) error {
+	if len(c.config.AllowedTypes) == 0 {
+		return nil
+	}
+	for _, allowed := range c.config.AllowedTypes {
+		if strings.EqualFold(strings.TrimSpace(allowed), strings.TrimSpace(typ)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q is not one of %s", ErrTypeNotAllowed, typ, strings.Join(c.config.AllowedTypes, ", "))
+}
+
+// checkZoneSize enforces Config.MaxRecordsPerZone before a create.
+func (c *Client) checkZoneSize(ctx context.Context, domain string) error {
+	limit := c.config.MaxRecordsPerZone
+	if limit <= 0 {
+		return nil
+	}
+	records, err := c.retrieveRecords(ctx, domain)
 	if err != nil {
 		return err
 	}
-	res, err := requireOK(
-		c.config.Client.Post(
-			fmt.Sprintf(PORKBUN_DNS_EDIT, domain, id),
-			"application/json",
-			bytes.NewBuffer(authjson)),
-	)
+	if len(records) >= limit {
+		return fmt.Errorf("%w: %s has %d records, the limit is %d", ErrZoneFull, domain, len(records), limit)
+	}
+	return nil
+}
+
+// EditRecord replaces the record with the given id by dnsrecord. Porkbun
+// resets the priority of an MX or SRV record edited without one, so an empty
+// Prio on those types keeps the current priority, costing one retrieve.
+func (c *Client) EditRecord(domain string, id string, dnsrecord *DNSRecord) error {
+	return c.editRecord(context.Background(), domain, id, dnsrecord)
+}
+
+func (c *Client) editRecord(ctx context.Context, domain string, id string, dnsrecord *DNSRecord) error {
+	return c.editRecordNotes(ctx, domain, id, dnsrecord, false)
+}
+
+// fillRecord returns r with its empty fields taken from current.
+func fillRecord(r DNSRecord, current *DNSRecord) DNSRecord {
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&r.Name, current.Name)
+	fill(&r.Type, current.Type)
+	fill(&r.Content, current.Content)
+	fill(&r.TTL, current.TTL)
+	fill(&r.Prio, current.Prio)
+	fill(&r.Notes, current.Notes)
+	return r
+}
+
+// recordWithNotes always sends the notes field, which DNSRecord omits when
+// empty, so that an empty string clears the notes of an existing record.
+type recordWithNotes struct {
+	DNSRecord
+	Notes string `json:"notes"`
+}
+
+// editRecordNotes is editRecord that, with explicitNotes, sends an empty
+// Notes as well, clearing the record's notes.
+func (c *Client) editRecordNotes(ctx context.Context, domain string, id string, dnsrecord *DNSRecord, explicitNotes bool) error {
+	if err := requireDomainAndID(domain, id); err != nil {
+		return err
+	}
+	// One retrieve serves the checks below and the audit event.
+	var current *DNSRecord
+	if c.config.SafeEdit || (dnsrecord.Prio == "" && hasPriority(dnsrecord.Type)) || len(c.config.AllowedTypes) > 0 {
+		var err error
+		if current, err = c.retrieveRecord(ctx, domain, id); err != nil {
+			return err
+		}
+		if err := c.checkType(current.Type); err != nil {
+			return err
+		}
+		filled := *dnsrecord
+		if c.config.SafeEdit {
+			filled = fillRecord(filled, current)
+		} else if filled.Prio == "" && hasPriority(filled.Type) {
+			filled.Prio = current.Prio
+		}
+		dnsrecord = &filled
+	} else {
+		current = c.auditBefore(ctx, domain, id)
+	}
+	if err := c.checkType(dnsrecord.Type); err != nil {
+		return err
+	}
+	var payload interface{} = c.recordPayload(domain, dnsrecord)
+	if explicitNotes {
+		payload = recordWithNotes{DNSRecord: c.recordPayload(domain, dnsrecord), Notes: dnsrecord.Notes}
+	}
+	authjson, err := c.getPayloadWithAuthJson(payload)
 	if err != nil {
 		return err
 	}
-	defer res.Body.Close()
-	_, e := extractDNSResponse(res, err)
-	return e
+	if err := c.postWrite(ctx, endpoint(PORKBUN_DNS_EDIT, domain, id), authjson, &DNSResponse{}, false); err != nil {
+		return err
+	}
+	c.audit(AUDIT_OP_EDIT, domain, id, current, dnsrecord)
+	return nil
 }
 
 func (c *Client) DeleteRecord(domain string, id string) error {
+	return c.deleteRecord(context.Background(), domain, id)
+}
+
+func (c *Client) deleteRecord(ctx context.Context, domain string, id string) error {
+	if err := requireDomainAndID(domain, id); err != nil {
+		return err
+	}
+	// One retrieve serves the type check and the audit event.
+	var current *DNSRecord
+	if len(c.config.AllowedTypes) > 0 {
+		var err error
+		if current, err = c.retrieveRecord(ctx, domain, id); err != nil {
+			return err
+		}
+		if err := c.checkType(current.Type); err != nil {
+			return err
+		}
+	} else {
+		current = c.auditBefore(ctx, domain, id)
+	}
 	authjson, err := c.getAuthJson()
 	if err != nil {
 		return err
 	}
-	res, err := requireOK(
-		c.config.Client.Post(
-			fmt.Sprintf(PORKBUN_DNS_DELETE, domain, id),
-			"application/json",
-			bytes.NewBuffer(authjson)),
-	)
-	if err != nil {
+	if err := c.postWrite(ctx, endpoint(PORKBUN_DNS_DELETE, domain, id), authjson, &DNSResponse{}, true); err != nil {
 		return err
 	}
-	defer res.Body.Close()
-	_, e := extractDNSResponse(res, err)
-	return e
+	c.audit(AUDIT_OP_DELETE, domain, id, current, nil)
+	return nil
+}
+
+// RetrieveRecord returns the record with the given id, or an error wrapping
+// ErrRecordNotFound if the domain has no such record.
+func (c *Client) RetrieveRecord(domain string, id string) (*DNSRecord, error) {
+	return c.retrieveRecord(context.Background(), domain, id)
+}
+
+func (c *Client) retrieveRecord(ctx context.Context, domain string, id string) (*DNSRecord, error) {
+	if err := requireDomainAndID(domain, id); err != nil {
+		return nil, err
+	}
+	authjson, err := c.getAuthJson()
+	if err != nil {
+		return nil, err
+	}
+	var d DNSResponse
+	if err := c.post(ctx, endpoint(PORKBUN_DNS_RETRIEVE_ID, domain, id), authjson, &d); err != nil {
+		return nil, err
+	}
+	if len(d.Records) == 0 {
+		return nil, fmt.Errorf("%w: id %s in %s", ErrRecordNotFound, id, domain)
+	}
+	c.observe(domain, d.Records[0])
+	return d.Records[0], nil
 }
 
 func (c *Client) RetrieveRecords(domain string) ([]*DNSRecord, error) {
+	return c.retrieveRecords(context.Background(), domain)
+}
+
+func (c *Client) retrieveRecords(ctx context.Context, domain string) ([]*DNSRecord, error) {
+	if err := requireDomain(domain); err != nil {
+		return nil, err
+	}
 	authjson, err := c.getAuthJson()
 	if err != nil {
 		return nil, err
 	}
-	res, err := requireOK(
-		c.config.Client.Post(
-			fmt.Sprintf(PORKBUN_DNS_RETRIEVE, domain),
-			"application/json",
-			bytes.NewBuffer(authjson)),
-	)
+	var d DNSResponse
+	if err := c.post(ctx, endpoint(PORKBUN_DNS_RETRIEVE, domain), authjson, &d); err != nil {
+		return nil, err
+	}
+	c.observe(domain, d.Records...)
+	// An empty zone may come back with an empty or absent records array;
+	// always hand callers a non-nil slice.
+	if d.Records == nil {
+		return []*DNSRecord{}, nil
+	}
+	return d.Records, nil
+}
+
+// RawRecord is a retrieved record together with the JSON the API sent for
+// it, which keeps any fields DNSRecord does not model.
+type RawRecord struct {
+	Record *DNSRecord
+	Raw    json.RawMessage
+}
+
+type rawRecordsResponse struct {
+	Status  string            `json:"status,omitempty"`
+	Message string            `json:"message,omitempty"`
+	Records []json.RawMessage `json:"records,omitempty"`
+}
+
+func (r *rawRecordsResponse) status() (string, string) { return r.Status, r.Message }
+
+// RetrieveRecordsRaw is RetrieveRecords that also returns the original JSON
+// of every record, for lossless backups and for reporting fields the
+// decoding drops.
+func (c *Client) RetrieveRecordsRaw(domain string) ([]RawRecord, error) {
+	if err := requireDomain(domain); err != nil {
+		return nil, err
+	}
+	authjson, err := c.getAuthJson()
+	if err != nil {
+		return nil, err
+	}
+	var d rawRecordsResponse
+	if err := c.post(context.Background(), endpoint(PORKBUN_DNS_RETRIEVE, domain), authjson, &d); err != nil {
+		return nil, err
+	}
+	records := make([]RawRecord, 0, len(d.Records))
+	for _, raw := range d.Records {
+		record := &DNSRecord{}
+		if err := json.Unmarshal(raw, record); err != nil {
+			return nil, fmt.Errorf("decoding record %s: %w", snippet(c.redact(string(raw))), err)
+		}
+		records = append(records, RawRecord{Record: record, Raw: raw})
+	}
+	return records, nil
+}
+
+// RetrieveRecordsByNameType returns the records of the given type at
+// subdomain ("" for the apex).
+func (c *Client) RetrieveRecordsByNameType(domain string, recordType string, subdomain string) ([]*DNSRecord, error) {
+	return c.retrieveRecordsByNameType(context.Background(), domain, recordType, subdomain)
+}
+
+func (c *Client) retrieveRecordsByNameType(ctx context.Context, domain string, recordType string, subdomain string) ([]*DNSRecord, error) {
+	if err := requireDomain(domain); err != nil {
+		return nil, err
+	}
+	if recordType == "" {
+		return nil, fmt.Errorf("record type must not be empty")
+	}
+	authjson, err := c.getAuthJson()
 	if err != nil {
 		return nil, err
 	}
-	defer res.Body.Close()
-	d, e := extractDNSResponse(res, err)
-	return d.Records, e
+	url := endpoint(PORKBUN_DNS_RETRIEVE_NAME_TYPE, domain, strings.ToUpper(recordType), relativeName(subdomain, domain))
+	var d DNSResponse
+	if err := c.post(ctx, url, authjson, &d); err != nil {
+		return nil, err
+	}
+	c.observe(domain, d.Records...)
+	if d.Records == nil {
+		return []*DNSRecord{}, nil
+	}
+	return d.Records, nil
 }
@@ -0,0 +1,601 @@
+package porkbun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// capturedRequest is a request as the test server received it.
+type capturedRequest struct {
+	Path          string
+	Header        http.Header
+	ContentLength int64
+	Body          []byte
+}
+
+// field decodes the top-level field key of the request body.
+func (r capturedRequest) field(t *testing.T, key string) (interface{}, bool) {
+	t.Helper()
+	var body map[string]interface{}
+	if err := json.Unmarshal(r.Body, &body); err != nil {
+		t.Fatalf("request body %s is not a JSON object: %v", r.Body, err)
+	}
+	value, ok := body[key]
+	return value, ok
+}
+
+// testServer is an API stand-in that records every request it serves.
+type testServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []capturedRequest
+}
+
+// newTestServer starts a server answering with handler and a client pointed
+// at it. cfg may be nil; its Auth and BaseURL are filled in.
+func newTestServer(t *testing.T, cfg *Config, handler http.HandlerFunc) (*testServer, *Client) {
+	t.Helper()
+	ts := &testServer{}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts.mu.Lock()
+		ts.requests = append(ts.requests, capturedRequest{Path: r.URL.EscapedPath(), Header: r.Header.Clone(), ContentLength: r.ContentLength, Body: body})
+		ts.mu.Unlock()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		handler(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	if cfg == nil {
+		cfg = &Config{}
+	}
+	cfg.Auth = Auth{APIKey: "pk1_test", SecretAPIKey: "sk1_test"}
+	cfg.BaseURL = ts.URL
+	c, err := NewClient(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ts, c
+}
+
+// Requests returns the requests served so far.
+func (ts *testServer) Requests() []capturedRequest {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return append([]capturedRequest(nil), ts.requests...)
+}
+
+// last returns the most recent request, failing the test if there is none.
+func (ts *testServer) last(t *testing.T) capturedRequest {
+	t.Helper()
+	requests := ts.Requests()
+	if len(requests) == 0 {
+		t.Fatal("no request was sent")
+	}
+	return requests[len(requests)-1]
+}
+
+// respond answers every request with status and body.
+func respond(status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+func TestRetrieveRecordsEmptyZone(t *testing.T) {
+	for _, body := range []string{
+		`{"status":"SUCCESS","records":[]}`,
+		`{"status":"SUCCESS"}`,
+		`{"status":"SUCCESS","records":null}`,
+	} {
+		_, c := newTestServer(t, nil, respond(http.StatusOK, body))
+		records, err := c.RetrieveRecords("example.com")
+		if err != nil {
+			t.Fatalf("%s: %v", body, err)
+		}
+		if records == nil || len(records) != 0 {
+			t.Errorf("%s: got %#v, want an empty non-nil slice", body, records)
+		}
+	}
+}
+
+// retrievePayload is a retrieve response as Porkbun sends it.
+const retrievePayload = `{"status":"SUCCESS","cloudflare":"enabled","records":[
+	{"id":"106926659","name":"www.example.com","type":"A","content":"1.1.1.1","ttl":"600","prio":"0","notes":"web"},
+	{"id":"106926660","name":"example.com","type":"MX","content":"mx.example.com","ttl":"3600","prio":"10","notes":""}]}`
+
+func TestRetrievedRecordRoundTrip(t *testing.T) {
+	ts, c := newTestServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dns/retrieve/example.com" {
+			respond(http.StatusOK, retrievePayload)(w, r)
+			return
+		}
+		respond(http.StatusOK, `{"status":"SUCCESS"}`)(w, r)
+	})
+	records, err := c.RetrieveRecords("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DNSRecord{ID: "106926659", Name: "www.example.com", Type: "A", Content: "1.1.1.1", TTL: "600", Notes: "web"}
+	if len(records) != 2 || *records[0] != want {
+		t.Fatalf("got %+v, want %+v first", records, want)
+	}
+	if err := c.EditRecord("example.com", records[0].ID, records[0]); err != nil {
+		t.Fatal(err)
+	}
+	req := ts.last(t)
+	if req.Path != "/dns/edit/example.com/106926659" {
+		t.Errorf("edit sent to %s", req.Path)
+	}
+	for key, value := range map[string]string{"name": "www", "type": "A", "content": "1.1.1.1", "ttl": "600", "notes": "web"} {
+		if got, _ := req.field(t, key); got != value {
+			t.Errorf("edit body %s = %v, want %q", key, got, value)
+		}
+	}
+	if _, ok := req.field(t, "prio"); ok {
+		t.Errorf("edit of an A record sent a prio: %s", req.Body)
+	}
+}
+
+func TestEmptyDomainOrID(t *testing.T) {
+	ts, c := newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS"}`))
+	record := &DNSRecord{Type: "A", Content: "1.1.1.1"}
+	// errOf drops the result of a call returning one value and an error.
+	errOf := func(_ interface{}, err error) error { return err }
+	calls := map[string]func() error{
+		"CreateRecord":              func() error { return errOf(c.CreateRecord("", record)) },
+		"EditRecord":                func() error { return c.EditRecord("", "1", record) },
+		"EditRecord id":             func() error { return c.EditRecord("example.com", "", record) },
+		"DeleteRecord":              func() error { return c.DeleteRecord("", "1") },
+		"DeleteRecord id":           func() error { return c.DeleteRecord("example.com", "") },
+		"RetrieveRecord":            func() error { return errOf(c.RetrieveRecord("", "1")) },
+		"RetrieveRecord id":         func() error { return errOf(c.RetrieveRecord("example.com", "")) },
+		"RetrieveRecords":           func() error { return errOf(c.RetrieveRecords("")) },
+		"RetrieveRecordsRaw":        func() error { return errOf(c.RetrieveRecordsRaw("")) },
+		"RetrieveRecordsByNameType": func() error { return errOf(c.RetrieveRecordsByNameType("", "A", "www")) },
+		"GetNameservers":            func() error { return errOf(c.GetNameservers("")) },
+		"GetURLForwards":            func() error { return errOf(c.GetURLForwards("")) },
+		"AddURLForward":             func() error { return c.AddURLForward("", &URLForward{}) },
+		"DeleteURLForward id":       func() error { return c.DeleteURLForward("example.com", "") },
+		"RetrieveSSLBundle":         func() error { return errOf(c.RetrieveSSLBundle("")) },
+		"GetDNSSECRecords":          func() error { return errOf(c.GetDNSSECRecords("")) },
+	}
+	for name, call := range calls {
+		err := call()
+		if err == nil || !strings.Contains(err.Error(), "must not be empty") {
+			t.Errorf("%s: got %v, want a must not be empty error", name, err)
+		}
+	}
+	if n := len(ts.Requests()); n != 0 {
+		t.Errorf("%d requests were sent for invalid input", n)
+	}
+}
+
+func TestPathSegmentsAreEscaped(t *testing.T) {
+	ts, c := newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS","records":[]}`))
+	if _, err := c.RetrieveRecordsByNameType("example.com", "txt", "_acme challenge/x?y#z"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ts.last(t).Path, "/dns/retrieveByNameType/example.com/TXT/_acme%20challenge%2Fx%3Fy%23z"; got != want {
+		t.Errorf("path %s, want %s", got, want)
+	}
+	if _, err := c.RetrieveRecord("example.com", "1/../2"); err == nil {
+		t.Fatal("want a not found error")
+	}
+	if got, want := ts.last(t).Path, "/dns/retrieve/example.com/1%2F..%2F2"; got != want {
+		t.Errorf("path %s, want %s", got, want)
+	}
+}
+
+// TestConcurrentUse is meant to be run with -race.
+func TestConcurrentUse(t *testing.T) {
+	_, c := newTestServer(t, &Config{MaxRetries: 1, RetryBudget: 10, RequestsPerSecond: 1000, TrackRecordHistory: true},
+		respond(http.StatusOK, retrievePayload))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if i%4 == 0 {
+					if err := c.SetAuth(Auth{APIKey: "pk1_rotated", SecretAPIKey: "sk1_rotated"}); err != nil {
+						t.Error(err)
+					}
+					continue
+				}
+				if _, err := c.RetrieveRecords("example.com"); err != nil {
+					t.Error(err)
+				}
+				c.LastQuota()
+				c.LastStatus()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestHTTP200WithErrorStatus(t *testing.T) {
+	for _, tc := range []struct {
+		status int
+		body   string
+	}{
+		{http.StatusOK, `{"status":"ERROR","message":"Invalid domain."}`},
+		{http.StatusBadRequest, `{"status":"ERROR","message":"Invalid domain."}`},
+	} {
+		_, c := newTestServer(t, nil, respond(tc.status, tc.body))
+		_, err := c.RetrieveRecords("example.com")
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatalf("HTTP %d: got %v, want an *APIError", tc.status, err)
+		}
+		if apiErr.StatusCode != tc.status || apiErr.Status != "ERROR" || apiErr.Message != "Invalid domain." {
+			t.Errorf("HTTP %d: got %+v", tc.status, apiErr)
+		}
+	}
+}
+
+func benchmarkClient(b *testing.B) *Client {
+	c, err := NewClient(&Config{Auth: Auth{APIKey: "pk1_test", SecretAPIKey: "sk1_test"}})
+	if err != nil {
+		b.Fatal(err)
+	}
+	return c
+}
+
+// BenchmarkMarshalAuth is the per-request cost getAuthJson avoids.
+func BenchmarkMarshalAuth(b *testing.B) {
+	auth := Auth{APIKey: "pk1_test", SecretAPIKey: "sk1_test"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(auth); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetAuthJson(b *testing.B) {
+	c := benchmarkClient(b)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.getAuthJson(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkGetDNSRecordWithAuthJson(b *testing.B) {
+	c := benchmarkClient(b)
+	record := &DNSRecord{Name: "www", Type: "A", Content: "1.1.1.1", TTL: "600"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.getDNSRecordWithAuthJson("example.com", record); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRetrieveRecords(b *testing.B) {
+	ts := httptest.NewServer(respond(http.StatusOK, retrievePayload))
+	defer ts.Close()
+	c, err := NewClient(&Config{Auth: Auth{APIKey: "pk1_test", SecretAPIKey: "sk1_test"}, BaseURL: ts.URL})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.RetrieveRecords("example.com"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestUnreadBodyKeepsConnection(t *testing.T) {
+	body := `{"status":"SUCCESS","records":[` + strings.Repeat(" ", 4096) + `]}`
+	_, c := newTestServer(t, &Config{MaxResponseBytes: 64, TrackConnections: true}, respond(http.StatusOK, body))
+	for i := 0; i < 3; i++ {
+		if _, err := c.RetrieveRecords("example.com"); !errors.Is(err, ErrResponseTooLarge) {
+			t.Fatalf("got %v, want ErrResponseTooLarge", err)
+		}
+	}
+	if stats := c.ConnectionStats(); stats.New != 1 || stats.Reused != 2 {
+		t.Errorf("got %+v, want one connection reused twice", stats)
+	}
+}
+
+func TestCancelAfterHeaders(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	_, c := newTestServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/dns/retrieve/slow.example" {
+			w.WriteHeader(http.StatusOK)
+			io.WriteString(w, `{"status":"SUCCESS",`)
+			w.(http.Flusher).Flush()
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		respond(http.StatusOK, `{"status":"SUCCESS","records":[]}`)(w, r)
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{GotFirstResponseByte: cancel})
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.retrieveRecords(ctx, "slow.example")
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("got %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled request did not return")
+	}
+	if _, err := c.RetrieveRecords("example.com"); err != nil {
+		t.Errorf("client unusable after a cancelled request: %v", err)
+	}
+}
+
+func TestEditPayloadShape(t *testing.T) {
+	ts, c := newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS"}`))
+	record := &DNSRecord{ID: "106926659", Name: "www.example.com", Type: "MX", Content: "mx.example.com.", TTL: "600", Prio: "10", Notes: "mail"}
+	if err := c.EditRecord("example.com", "106926659", record); err != nil {
+		t.Fatal(err)
+	}
+	req := ts.last(t)
+	want := `{"apikey":"pk1_test","secretapikey":"sk1_test","name":"www","type":"MX","content":"mx.example.com","ttl":"600","prio":"10","notes":"mail"}`
+	if string(req.Body) != want || req.Path != "/dns/edit/example.com/106926659" {
+		t.Errorf("got %s %s\nwant /dns/edit/example.com/106926659 %s", req.Path, req.Body, want)
+	}
+}
+
+func TestMissingStatus(t *testing.T) {
+	for _, body := range []string{`{}`, ``, `{"records":[]}`} {
+		_, c := newTestServer(t, nil, respond(http.StatusOK, body))
+		_, err := c.RetrieveRecords("example.com")
+		if !errors.Is(err, ErrMalformedResponse) {
+			t.Errorf("%q: got %v, want ErrMalformedResponse", body, err)
+			continue
+		}
+		if body != "" && !strings.Contains(err.Error(), body) {
+			t.Errorf("%q: error %q does not include the body", body, err)
+		}
+	}
+	_, c := newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS"}`))
+	if _, err := c.CreateRecord("example.com", &DNSRecord{Type: "A", Content: "1.1.1.1"}); !errors.Is(err, ErrMalformedResponse) {
+		t.Errorf("create without id: got %v, want ErrMalformedResponse", err)
+	}
+}
+
+func TestRequestsCarryContentLength(t *testing.T) {
+	ts, c := newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS","id":1}`))
+	if _, err := c.CreateRecord("example.com", &DNSRecord{Type: "TXT", Content: strings.Repeat("x", 8192)}); err != nil {
+		t.Fatal(err)
+	}
+	if req := ts.last(t); req.ContentLength != int64(len(req.Body)) {
+		t.Errorf("Content-Length %d for a %d byte body", req.ContentLength, len(req.Body))
+	}
+}
+
+func TestMaintenancePage(t *testing.T) {
+	const page = "<!DOCTYPE html>\n<html><body><h1>Porkbun is down for maintenance</h1></body></html>"
+	for _, tc := range []struct {
+		status      int
+		contentType string
+	}{
+		{http.StatusOK, "text/html; charset=utf-8"},
+		{http.StatusOK, "application/json"},
+		{http.StatusServiceUnavailable, "text/html"},
+	} {
+		_, c := newTestServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", tc.contentType)
+			w.WriteHeader(tc.status)
+			io.WriteString(w, page)
+		})
+		_, err := c.RetrieveRecords("example.com")
+		if !errors.Is(err, ErrServiceMaintenance) {
+			t.Errorf("HTTP %d %s: got %v, want ErrServiceMaintenance", tc.status, tc.contentType, err)
+		}
+	}
+}
+
+// fakeZone is an in-memory stand-in for the DNS endpoints of one domain.
+type fakeZone struct {
+	domain  string
+	mu      sync.Mutex
+	nextID  int
+	records []*DNSRecord
+}
+
+func newFakeZone(domain string, records ...*DNSRecord) *fakeZone {
+	z := &fakeZone{domain: domain, nextID: 1000}
+	for _, record := range records {
+		z.add(*record)
+	}
+	return z
+}
+
+// add stores record the way Porkbun returns it, with an ID and a fully
+// qualified name.
+func (z *fakeZone) add(record DNSRecord) string {
+	z.nextID++
+	record.ID = strconv.Itoa(z.nextID)
+	record.Name = (&record).FQDN(z.domain)
+	if record.TTL == "" {
+		record.TTL = "600"
+	}
+	z.records = append(z.records, &record)
+	return record.ID
+}
+
+// Records returns a copy of the zone's records.
+func (z *fakeZone) Records() []DNSRecord {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	records := make([]DNSRecord, len(z.records))
+	for i, record := range z.records {
+		records[i] = *record
+	}
+	return records
+}
+
+func (z *fakeZone) find(id string) int {
+	for i, record := range z.records {
+		if record.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (z *fakeZone) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	z.mu.Lock()
+	defer z.mu.Unlock()
+	var body DNSRecord
+	json.NewDecoder(r.Body).Decode(&body)
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "dns" || parts[2] != z.domain {
+		respond(http.StatusBadRequest, `{"status":"ERROR","message":"Invalid domain."}`)(w, r)
+		return
+	}
+	notFound := respond(http.StatusBadRequest, `{"status":"ERROR","message":"Invalid record ID."}`)
+	reply := func(v interface{}) {
+		data, _ := json.Marshal(v)
+		respond(http.StatusOK, string(data))(w, r)
+	}
+	switch op := parts[1]; {
+	case op == "create" && len(parts) == 3:
+		reply(map[string]interface{}{"status": "SUCCESS", "id": json.Number(z.add(body))})
+	case op == "edit" && len(parts) == 4:
+		i := z.find(parts[3])
+		if i < 0 {
+			notFound(w, r)
+			return
+		}
+		body.ID = parts[3]
+		body.Name = (&body).FQDN(z.domain)
+		if body.TTL == "" {
+			body.TTL = "600"
+		}
+		z.records[i] = &body
+		reply(map[string]string{"status": "SUCCESS"})
+	case op == "delete" && len(parts) == 4:
+		i := z.find(parts[3])
+		if i < 0 {
+			notFound(w, r)
+			return
+		}
+		z.records = append(z.records[:i], z.records[i+1:]...)
+		reply(map[string]string{"status": "SUCCESS"})
+	case op == "retrieve" && len(parts) == 3:
+		reply(map[string]interface{}{"status": "SUCCESS", "records": z.records})
+	case op == "retrieve" && len(parts) == 4:
+		i := z.find(parts[3])
+		if i < 0 {
+			reply(map[string]interface{}{"status": "SUCCESS", "records": []*DNSRecord{}})
+			return
+		}
+		reply(map[string]interface{}{"status": "SUCCESS", "records": z.records[i : i+1]})
+	case op == "retrieveByNameType" && len(parts) >= 4:
+		name := ""
+		if len(parts) == 5 {
+			name = parts[4]
+		}
+		matches := []*DNSRecord{}
+		for _, record := range z.records {
+			if strings.EqualFold(record.Type, parts[3]) && nameKey(record.Name, z.domain) == strings.ToLower(name) {
+				matches = append(matches, record)
+			}
+		}
+		reply(map[string]interface{}{"status": "SUCCESS", "records": matches})
+	default:
+		respond(http.StatusNotFound, `{"status":"ERROR","message":"Unknown endpoint."}`)(w, r)
+	}
+}
+
+func TestResponseExtraFields(t *testing.T) {
+	// Porkbun's retrieve adds a cloudflare field next to the records.
+	retrieve := `{"status":"SUCCESS","cloudflare":"enabled","records":[]}`
+	for _, tt := range []struct {
+		format  string
+		payload string
+		out     statusResponse
+		want    map[string]string
+	}{
+		{PORKBUN_DNS_CREATE, `{"status":"SUCCESS","id":106926659}`, &CreateResponse{}, nil},
+		{PORKBUN_DNS_CREATE, `{"status":"SUCCESS","id":106926659,"state":"queued","queue":{"position":3}}`, &CreateResponse{},
+			map[string]string{"state": `"queued"`, "queue": `{"position":3}`}},
+		{PORKBUN_DNS_RETRIEVE, retrieve, &DNSResponse{}, map[string]string{"cloudflare": `"enabled"`}},
+	} {
+		_, c := newTestServer(t, nil, respond(http.StatusOK, tt.payload))
+		if err := c.post(context.Background(), endpoint(tt.format, "example.com"), []byte(`{}`), tt.out); err != nil {
+			t.Fatalf("%s: %v", tt.payload, err)
+		}
+		var extra map[string]json.RawMessage
+		switch out := tt.out.(type) {
+		case *CreateResponse:
+			extra = out.Extra
+			if out.Id.String() != "106926659" {
+				t.Errorf("%s: id = %s", tt.payload, out.Id)
+			}
+		case *DNSResponse:
+			extra = out.Extra
+		}
+		if len(extra) != len(tt.want) {
+			t.Errorf("%s: extra = %s, want %v", tt.payload, extra, tt.want)
+		}
+		for key, want := range tt.want {
+			if got := string(extra[key]); got != want {
+				t.Errorf("%s: extra[%s] = %s, want %s", tt.payload, key, got, want)
+			}
+		}
+	}
+}
+
+func TestAcceptedResponse(t *testing.T) {
+	_, c := newTestServer(t, nil, respond(http.StatusAccepted, `{"status":"SUCCESS","id":106926661}`))
+	id, err := c.CreateRecord("example.com", &DNSRecord{Name: "www", Type: "A", Content: "1.1.1.1"})
+	if err != nil {
+		t.Fatalf("202 with SUCCESS: %v", err)
+	}
+	if id != "106926661" || c.LastStatus() != http.StatusAccepted {
+		t.Errorf("id %s, LastStatus %d, want 106926661 and 202", id, c.LastStatus())
+	}
+	var d CreateResponse
+	if err := c.post(context.Background(), endpoint(PORKBUN_DNS_CREATE, "example.com"), []byte(`{}`), &d); err != nil {
+		t.Fatal(err)
+	}
+	if !d.Accepted {
+		t.Error("Accepted not set on a 202 response")
+	}
+
+	_, c = newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS","id":106926661}`))
+	d = CreateResponse{}
+	if err := c.post(context.Background(), endpoint(PORKBUN_DNS_CREATE, "example.com"), []byte(`{}`), &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Accepted {
+		t.Error("Accepted set on a 200 response")
+	}
+
+	_, c = newTestServer(t, nil, respond(http.StatusAccepted, `{"status":"ERROR","message":"Invalid domain."}`))
+	var apiErr *APIError
+	if _, err := c.CreateRecord("example.com", &DNSRecord{Name: "www", Type: "A", Content: "1.1.1.1"}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusAccepted {
+		t.Errorf("202 with ERROR: got %v, want an *APIError", err)
+	}
+}
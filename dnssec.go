@@ -0,0 +1,329 @@
+package porkbun
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const PORKBUN_DNS_GET_DNSSEC = PORKBUN_DNS_BASE + "/getDnssecRecords/%s"
+
+// DEFAULT_DS_RESOLVER is the public resolver CheckDSPublished asks when none
+// is given.
+const DEFAULT_DS_RESOLVER = "1.1.1.1"
+
+// DSRecord is a DNSSEC delegation signer record, e.g.
+//
+//	{"keyTag":"64087","alg":"13","digestType":"2","digest":"15E44587..."}
+//
+// Digest is hex and compares case-insensitively.
+type DSRecord struct {
+	KeyTag     string `json:"keyTag"`
+	Alg        string `json:"alg"`
+	DigestType string `json:"digestType"`
+	Digest     string `json:"digest"`
+}
+
+// matches reports whether r and o describe the same key.
+func (r *DSRecord) matches(o *DSRecord) bool {
+	return r.KeyTag == o.KeyTag && r.Alg == o.Alg && r.DigestType == o.DigestType && strings.EqualFold(r.Digest, o.Digest)
+}
+
+// DNSSECResponse is the body of the getDnssecRecords endpoint. Porkbun
+// returns the records as an object keyed by key tag, or as an empty list
+// when there are none, so Records is decoded by GetDNSSECRecords.
+type DNSSECResponse struct {
+	Status  string          `json:"status,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Records json.RawMessage `json:"records,omitempty"`
+}
+
+func (r *DNSSECResponse) status() (string, string) { return r.Status, r.Message }
+
+// GetDNSSECRecords returns the DS records Porkbun holds for domain at the
+// registry, ordered by key tag. Domains without DNSSEC have none.
+func (c *Client) GetDNSSECRecords(domain string) ([]*DSRecord, error) {
+	if err := requireDomain(domain); err != nil {
+		return nil, err
+	}
+	authjson, err := c.getAuthJson()
+	if err != nil {
+		return nil, err
+	}
+	var d DNSSECResponse
+	if err := c.post(context.Background(), endpoint(PORKBUN_DNS_GET_DNSSEC, domain), authjson, &d); err != nil {
+		return nil, err
+	}
+	records := []*DSRecord{}
+	if len(d.Records) == 0 || d.Records[0] == '[' {
+		if err := json.Unmarshal(d.Records, &records); len(d.Records) > 0 && err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformedResponse, err)
+		}
+		return records, nil
+	}
+	var byTag map[string]*DSRecord
+	if err := json.Unmarshal(d.Records, &byTag); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedResponse, err)
+	}
+	for _, record := range byTag {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, _ := strconv.Atoi(records[i].KeyTag)
+		b, _ := strconv.Atoi(records[j].KeyTag)
+		return a < b
+	})
+	return records, nil
+}
+
+// DSStatus is the outcome of CheckDSPublished.
+type DSStatus struct {
+	// Published is set when a DS record held by Porkbun is visible at the
+	// parent zone.
+	Published bool
+	// Records are the DS records held by Porkbun, Parent those the resolver
+	// returned.
+	Records []*DSRecord
+	Parent  []*DSRecord
+}
+
+// String returns "published" or "not-published".
+func (s *DSStatus) String() string {
+	if s.Published {
+		return "published"
+	}
+	return "not-published"
+}
+
+// CheckDSPublished checks whether DNSSEC for domain is complete: it
+// retrieves the DS records Porkbun holds and asks resolver, an address such
+// as "8.8.8.8" or DEFAULT_DS_RESOLVER when empty, for the DS records
+// published at the parent. The status is published when one of them
+// matches. Resolvers cache, so a DS added moments ago may still show as not
+// published. The error is set when either lookup fails.
+func (c *Client) CheckDSPublished(ctx context.Context, domain string, resolver string) (*DSStatus, error) {
+	records, err := c.GetDNSSECRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	if resolver == "" {
+		resolver = DEFAULT_DS_RESOLVER
+	}
+	parent, err := lookupDS(ctx, resolver, asciiName(strings.TrimSuffix(domain, ".")))
+	if err != nil {
+		return nil, fmt.Errorf("looking up DS of %s at %s: %w", domain, resolver, err)
+	}
+	status := &DSStatus{Records: records, Parent: parent}
+	for _, record := range records {
+		for _, published := range parent {
+			if record.matches(published) {
+				status.Published = true
+			}
+		}
+	}
+	return status, nil
+}
+
+// dnsTypeDS is the wire type of DS records, which net.Resolver cannot look
+// up, so lookupDS speaks the DNS protocol itself.
+const dnsTypeDS = 43
+
+// lookupDS sends a single recursive DS query for name to resolver over UDP.
+func lookupDS(ctx context.Context, resolver string, name string) ([]*DSRecord, error) {
+	if _, _, err := net.SplitHostPort(resolver); err != nil {
+		resolver = net.JoinHostPort(resolver, "53")
+	}
+	query, id, err := dsQuery(name)
+	if err != nil {
+		return nil, err
+	}
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", resolver)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(5 * time.Second)
+	}
+	conn.SetDeadline(deadline)
+	if _, err := conn.Write(query); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 4096)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n >= 2 && binary.BigEndian.Uint16(buf) == id {
+			return parseDSAnswer(buf[:n], query)
+		}
+	}
+}
+
+// dsQuery builds the query message for the DS records of name, with EDNS0
+// advertising a 4096 byte buffer so answers are not truncated.
+func dsQuery(name string) ([]byte, uint16, error) {
+	var idBytes [2]byte
+	if _, err := rand.Read(idBytes[:]); err != nil {
+		return nil, 0, err
+	}
+	id := binary.BigEndian.Uint16(idBytes[:])
+	msg := []byte{idBytes[0], idBytes[1], 0x01, 0x00, 0, 1, 0, 0, 0, 0, 0, 1}
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 {
+			return nil, 0, fmt.Errorf("invalid domain name %q", name)
+		}
+		msg = append(msg, byte(len(label)))
+		msg = append(msg, label...)
+	}
+	msg = append(msg, 0, 0, dnsTypeDS, 0, 1)
+	// OPT pseudo-record: root name, type 41, class is the buffer size.
+	msg = append(msg, 0, 0, 41, 0x10, 0x00, 0, 0, 0, 0, 0, 0)
+	return msg, id, nil
+}
+
+var errShortDNSMessage = errors.New("short DNS message")
+
+// parseDSAnswer returns the DS records in the answer section of msg, the
+// reply to query as built by dsQuery. The reply must carry the ID and the
+// single question of the query; every read is bounds checked, as msg comes
+// off the network.
+func parseDSAnswer(msg []byte, query []byte) ([]*DSRecord, error) {
+	if len(msg) < 12 {
+		return nil, errShortDNSMessage
+	}
+	if msg[0] != query[0] || msg[1] != query[1] {
+		return nil, errors.New("DNS answer has the wrong ID")
+	}
+	if msg[2]&0x80 == 0 {
+		return nil, errors.New("DNS message is not a response")
+	}
+	if msg[2]&0x02 != 0 {
+		return nil, errors.New("DNS answer truncated")
+	}
+	switch rcode := msg[3] & 0x0f; rcode {
+	case 0, 3:
+		// NXDOMAIN simply means nothing is published.
+	default:
+		return nil, fmt.Errorf("DNS query failed with rcode %d", rcode)
+	}
+	if qdcount := binary.BigEndian.Uint16(msg[4:]); qdcount != 1 {
+		return nil, fmt.Errorf("DNS answer has %d questions, want 1", qdcount)
+	}
+	ancount := int(binary.BigEndian.Uint16(msg[6:]))
+	off, err := skipDNSName(msg, 12)
+	if err != nil {
+		return nil, err
+	}
+	off += 4
+	if off > len(msg) {
+		return nil, errShortDNSMessage
+	}
+	qend, _ := skipDNSName(query, 12)
+	if !sameQuestion(msg[12:off], query[12:qend+4]) {
+		return nil, errors.New("DNS answer is for another question")
+	}
+	records := []*DSRecord{}
+	for i := 0; i < ancount; i++ {
+		if off, err = skipDNSName(msg, off); err != nil {
+			return nil, err
+		}
+		if off+10 > len(msg) {
+			return nil, errShortDNSMessage
+		}
+		typ := binary.BigEndian.Uint16(msg[off:])
+		length := int(binary.BigEndian.Uint16(msg[off+8:]))
+		off += 10
+		if off+length > len(msg) {
+			return nil, errShortDNSMessage
+		}
+		rdata := msg[off : off+length]
+		off += length
+		if typ != dnsTypeDS || len(rdata) < 4 {
+			continue
+		}
+		records = append(records, &DSRecord{
+			KeyTag:     strconv.Itoa(int(binary.BigEndian.Uint16(rdata))),
+			Alg:        strconv.Itoa(int(rdata[2])),
+			DigestType: strconv.Itoa(int(rdata[3])),
+			Digest:     strings.ToUpper(hex.EncodeToString(rdata[4:])),
+		})
+	}
+	return records, nil
+}
+
+// sameQuestion reports whether the question sections a and b, an
+// uncompressed name followed by type and class, are equal. Names compare
+// case-insensitively since resolvers may echo them in another case.
+func sameQuestion(a []byte, b []byte) bool {
+	if len(a) != len(b) || len(a) < 4 {
+		return false
+	}
+	name := len(a) - 4
+	return strings.EqualFold(string(a[:name]), string(b[:name])) && string(a[name:]) == string(b[name:])
+}
+
+// skipDNSName returns the offset just past the possibly compressed name
+// starting at off. Pointers are not followed, and the reserved label types
+// 0x40 and 0x80 are rejected.
+func skipDNSName(msg []byte, off int) (int, error) {
+	for {
+		if off >= len(msg) {
+			return 0, errShortDNSMessage
+		}
+		n := int(msg[off])
+		switch {
+		case n == 0:
+			return off + 1, nil
+		case n&0xc0 == 0xc0:
+			if off+2 > len(msg) {
+				return 0, errShortDNSMessage
+			}
+			return off + 2, nil
+		case n&0xc0 != 0:
+			return 0, fmt.Errorf("DNS name uses reserved label type 0x%02x", n&0xc0)
+		}
+		off += n + 1
+	}
+}
+
+// FindOrphanedDNSSEC returns the DS records Porkbun holds for domain that
+// are not published at the parent zone, as seen by DEFAULT_DS_RESOLVER,
+// e.g. ones left over after DNSSEC was disabled or a key rolled back. They
+// are candidates for removal at Porkbun.
+func (c *Client) FindOrphanedDNSSEC(domain string) ([]*DSRecord, error) {
+	return c.findOrphanedDNSSEC(context.Background(), domain, "")
+}
+
+func (c *Client) findOrphanedDNSSEC(ctx context.Context, domain string, resolver string) ([]*DSRecord, error) {
+	status, err := c.CheckDSPublished(ctx, domain, resolver)
+	if err != nil {
+		return nil, err
+	}
+	orphaned := []*DSRecord{}
+	for _, record := range status.Records {
+		published := false
+		for _, p := range status.Parent {
+			if record.matches(p) {
+				published = true
+				break
+			}
+		}
+		if !published {
+			orphaned = append(orphaned, record)
+		}
+	}
+	return orphaned, nil
+}
@@ -0,0 +1,155 @@
+package porkbun
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const dnssecPayload = `{"status":"SUCCESS","records":{
+	"2371":{"keyTag":"2371","alg":"13","digestType":"2","digest":"c988ec423e3880eb8dd8a46cbb7f4b4a0f28ed8c8e3debe5f3e4a1ec6b3fd3cd"},
+	"64087":{"keyTag":"64087","alg":"13","digestType":"2","digest":"15E445BD141CDDA6AC1A3AE1F22ABE2B3D"}}}`
+
+// dsAnswer builds the reply to query with one DS record per rdata, each
+// using a compression pointer to the question name.
+func dsAnswer(query []byte, rdata ...[]byte) []byte {
+	qend, _ := skipDNSName(query, 12)
+	msg := []byte{query[0], query[1], 0x81, 0x80, 0, 1, 0, byte(len(rdata)), 0, 0, 0, 0}
+	msg = append(msg, query[12:qend+4]...)
+	for _, r := range rdata {
+		msg = append(msg, 0xc0, 12, 0, dnsTypeDS, 0, 1, 0, 0, 0x0e, 0x10, 0, byte(len(r)))
+		msg = append(msg, r...)
+	}
+	return msg
+}
+
+func dsRdata(keyTag uint16, alg byte, digestType byte, digest string) []byte {
+	d, _ := hex.DecodeString(digest)
+	r := []byte{0, 0, alg, digestType}
+	binary.BigEndian.PutUint16(r, keyTag)
+	return append(r, d...)
+}
+
+// fakeResolver answers every DS query on a local UDP port with reply.
+func fakeResolver(t *testing.T, reply func(query []byte) []byte) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			conn.WriteTo(reply(append([]byte(nil), buf[:n]...)), addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func TestDNSSECPublishedAndOrphaned(t *testing.T) {
+	_, c := newTestServer(t, nil, respond(http.StatusOK, dnssecPayload))
+	asked := make(chan string, 2)
+	resolver := fakeResolver(t, func(query []byte) []byte {
+		qend, _ := skipDNSName(query, 12)
+		asked <- string(query[12:qend])
+		return dsAnswer(query, dsRdata(2371, 13, 2, "C988EC423E3880EB8DD8A46CBB7F4B4A0F28ED8C8E3DEBE5F3E4A1EC6B3FD3CD"))
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	status, err := c.CheckDSPublished(ctx, "example.com", resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Published || status.String() != "published" || len(status.Records) != 2 || len(status.Parent) != 1 {
+		t.Errorf("status = %+v", status)
+	}
+	if name := <-asked; name != "\x07example\x03com\x00" {
+		t.Errorf("asked for %q", name)
+	}
+	orphaned, err := c.findOrphanedDNSSEC(ctx, "example.com", resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(orphaned) != 1 || orphaned[0].KeyTag != "64087" {
+		t.Errorf("orphaned = %+v, want key tag 64087", orphaned)
+	}
+}
+
+func TestDNSSECNotPublished(t *testing.T) {
+	_, c := newTestServer(t, nil, respond(http.StatusOK, dnssecPayload))
+	resolver := fakeResolver(t, func(query []byte) []byte {
+		msg := dsAnswer(query)
+		msg[3] |= 3 // NXDOMAIN
+		return msg
+	})
+	status, err := c.CheckDSPublished(context.Background(), "example.com", resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Published || status.String() != "not-published" || len(status.Parent) != 0 {
+		t.Errorf("status = %+v", status)
+	}
+}
+
+func TestParseDSAnswer(t *testing.T) {
+	query, _, err := dsQuery("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rdata := dsRdata(2371, 13, 2, "C988EC42")
+	good := dsAnswer(query, rdata)
+	records, err := parseDSAnswer(good, query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*DSRecord{{KeyTag: "2371", Alg: "13", DigestType: "2", Digest: "C988EC42"}}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %+v, want %+v", records, want)
+	}
+
+	mangle := func(f func(msg []byte) []byte) []byte {
+		return f(append([]byte(nil), good...))
+	}
+	qend, _ := skipDNSName(query, 12)
+	for name, msg := range map[string][]byte{
+		"empty":           {},
+		"header only":     good[:11],
+		"wrong id":        mangle(func(m []byte) []byte { m[0] ^= 0xff; return m }),
+		"query":           mangle(func(m []byte) []byte { m[2] &^= 0x80; return m }),
+		"truncated flag":  mangle(func(m []byte) []byte { m[2] |= 0x02; return m }),
+		"servfail":        mangle(func(m []byte) []byte { m[3] |= 2; return m }),
+		"no question":     mangle(func(m []byte) []byte { m[5] = 0; return m }),
+		"two questions":   mangle(func(m []byte) []byte { m[5] = 2; return m }),
+		"other name":      mangle(func(m []byte) []byte { m[13] = 'x'; return m }),
+		"other type":      mangle(func(m []byte) []byte { m[qend+1] = 1; return m }),
+		"reserved 0x40":   mangle(func(m []byte) []byte { m[12] = 0x47; return m }),
+		"reserved 0x80":   mangle(func(m []byte) []byte { m[12] = 0x87; return m }),
+		"short question":  good[:qend+2],
+		"cut pointer":     good[:qend+5],
+		"cut header":      good[:qend+10],
+		"cut rdata":       good[:len(good)-1],
+		"extra answer":    mangle(func(m []byte) []byte { m[7] = 2; return m }),
+		"long label":      mangle(func(m []byte) []byte { m[12] = 0x3f; return m }),
+		"answer reserved": mangle(func(m []byte) []byte { m[qend+4] = 0x80; return m }),
+	} {
+		if records, err := parseDSAnswer(msg, query); err == nil {
+			t.Errorf("%s: got %+v, want an error", name, records)
+		}
+	}
+
+	// Every prefix of a good answer must fail cleanly rather than panic.
+	for i := range good {
+		if _, err := parseDSAnswer(good[:i], query); err == nil {
+			t.Errorf("prefix of %d bytes parsed", i)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package porkbun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const PORKBUN_DOMAIN_BASE = PORKBUN_API_BASE + "/domain"
+const PORKBUN_DOMAIN_LIST_ALL = PORKBUN_DOMAIN_BASE + "/listAll"
+const PORKBUN_DOMAIN_GET_NS = PORKBUN_DOMAIN_BASE + "/getNs/%s"
+
+// PORKBUN_DOMAIN_PAGE_SIZE is the number of domains listAll returns per page.
+const PORKBUN_DOMAIN_PAGE_SIZE = 1000
+
+// PORKBUN_DATE_LAYOUT is the layout of the dates listAll returns, e.g.
+// "2024-03-01 17:25:11". The API gives no time zone; they are read as UTC.
+const PORKBUN_DATE_LAYOUT = "2006-01-02 15:04:05"
+
+// Domain is a domain in the account, as returned by listAll. Created and
+// Expires hold CreateDate and ExpireDate parsed with PORKBUN_DATE_LAYOUT,
+// and are zero when the API leaves a date out or sends one that does not
+// parse; the raw strings are kept either way.
+type Domain struct {
+	Domain       string        `json:"domain,omitempty"`
+	Status       string        `json:"status,omitempty"`
+	TLD          string        `json:"tld,omitempty"`
+	CreateDate   string        `json:"createDate,omitempty"`
+	ExpireDate   string        `json:"expireDate,omitempty"`
+	SecurityLock json.Number   `json:"securityLock,omitempty"`
+	WhoisPrivacy json.Number   `json:"whoisPrivacy,omitempty"`
+	AutoRenew    json.Number   `json:"autoRenew,omitempty"`
+	NotLocal     json.Number   `json:"notLocal,omitempty"`
+	Labels       []DomainLabel `json:"labels,omitempty"`
+	Created      time.Time     `json:"-"`
+	Expires      time.Time     `json:"-"`
+}
+
+// UnmarshalJSON decodes a domain and parses its dates. A bad date only
+// leaves its time zero, so that one odd entry does not fail a whole
+// listing.
+func (d *Domain) UnmarshalJSON(data []byte) error {
+	type plain Domain
+	if err := json.Unmarshal(data, (*plain)(d)); err != nil {
+		return err
+	}
+	d.Created = parsePorkbunDate(d.CreateDate)
+	d.Expires = parsePorkbunDate(d.ExpireDate)
+	return nil
+}
+
+// IsExpiringWithin reports whether the domain expires less than within
+// after now, usually time.Now(), or already has. It is false when the
+// expiry date is unknown.
+func (d *Domain) IsExpiringWithin(now time.Time, within time.Duration) bool {
+	return !d.Expires.IsZero() && d.Expires.Sub(now) < within
+}
+
+// IsLocked reports whether the registrar transfer lock, securityLock in the
+// listing, is on.
+func (d *Domain) IsLocked() bool {
+	return d.SecurityLock.String() == "1"
+}
+
+// parsePorkbunDate parses a listAll date, returning the zero time for
+// missing and invalid ones.
+func parsePorkbunDate(s string) time.Time {
+	if s == "" || strings.HasPrefix(s, "0000-00-00") {
+		return time.Time{}
+	}
+	t, err := time.Parse(PORKBUN_DATE_LAYOUT, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+type DomainLabel struct {
+	ID    string `json:"id,omitempty"`
+	Title string `json:"title,omitempty"`
+	Color string `json:"color,omitempty"`
+}
+
+type DomainListResponse struct {
+	Status  string    `json:"status,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Domains []*Domain `json:"domains,omitempty"`
+}
+
+func (r *DomainListResponse) status() (string, string) { return r.Status, r.Message }
+
+type domainListRequest struct {
+	Start         string `json:"start"`
+	IncludeLabels string `json:"includeLabels,omitempty"`
+}
+
+// ListDomains returns one page of the account's domains, starting at the
+// given offset. Pages hold up to PORKBUN_DOMAIN_PAGE_SIZE domains.
+func (c *Client) ListDomains(start int) ([]*Domain, error) {
+	return c.listDomains(context.Background(), start)
+}
+
+func (c *Client) listDomains(ctx context.Context, start int) ([]*Domain, error) {
+	authjson, err := c.getPayloadWithAuthJson(domainListRequest{
+		Start:         strconv.Itoa(start),
+		IncludeLabels: "yes",
+	})
+	if err != nil {
+		return nil, err
+	}
+	var d DomainListResponse
+	if err := c.post(ctx, PORKBUN_DOMAIN_LIST_ALL, authjson, &d); err != nil {
+		return nil, err
+	}
+	if d.Domains == nil {
+		return []*Domain{}, nil
+	}
+	return d.Domains, nil
+}
+
+// ListAllDomains pages through ListDomains and returns every domain in the
+// account.
+func (c *Client) ListAllDomains(ctx context.Context) ([]*Domain, error) {
+	all := []*Domain{}
+	for start := 0; ; {
+		page, err := c.listDomains(ctx, start)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, page...)
+		// A short page is the last one. When the last page happens to be
+		// exactly full, the following request returns an empty page.
+		if len(page) < PORKBUN_DOMAIN_PAGE_SIZE {
+			return all, nil
+		}
+		start += len(page)
+	}
+}
+
+// OwnsDomain reports whether domain belongs to the authenticated account,
+// so tooling can reject a mistyped domain before operating on it.
+func (c *Client) OwnsDomain(domain string) (bool, error) {
+	if err := requireDomain(domain); err != nil {
+		return false, err
+	}
+	domains, err := c.ListAllDomains(context.Background())
+	if err != nil {
+		return false, err
+	}
+	domain = strings.TrimSuffix(domain, ".")
+	for _, d := range domains {
+		if strings.EqualFold(d.Domain, domain) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// IsLocked reports whether the registrar transfer lock of domain is on, as
+// it must be off before transferring the domain away. Porkbun only exposes
+// the lock in the domain listing, so this pages through every domain of the
+// account; use Domain.IsLocked when the listing is at hand. A domain that is
+// not in the account is an error.
+func (c *Client) IsLocked(domain string) (bool, error) {
+	if err := requireDomain(domain); err != nil {
+		return false, err
+	}
+	domains, err := c.ListAllDomains(context.Background())
+	if err != nil {
+		return false, err
+	}
+	domain = strings.TrimSuffix(domain, ".")
+	for _, d := range domains {
+		if strings.EqualFold(d.Domain, domain) {
+			return d.IsLocked(), nil
+		}
+	}
+	return false, fmt.Errorf("%s is not a domain of this account", domain)
+}
+
+// porkbunNameservers are the authoritative nameservers Porkbun assigns to
+// the domains it hosts DNS for.
+var porkbunNameservers = map[string]bool{
+	"curitiba.ns.porkbun.com":  true,
+	"fortaleza.ns.porkbun.com": true,
+	"maceio.ns.porkbun.com":    true,
+	"salvador.ns.porkbun.com":  true,
+}
+
+// DefaultNameservers returns the nameservers to set at the registrar of a
+// domain registered elsewhere whose DNS is hosted at Porkbun, in sorted
+// order. UsingPorkbunNameservers checks a domain against them. The list is
+// static: Porkbun has no API for it, so it is compiled into this package and
+// only changes with a new release.
+func DefaultNameservers() []string {
+	ns := make([]string, 0, len(porkbunNameservers))
+	for host := range porkbunNameservers {
+		ns = append(ns, host)
+	}
+	sort.Strings(ns)
+	return ns
+}
+
+type NameserversResponse struct {
+	Status  string   `json:"status,omitempty"`
+	Message string   `json:"message,omitempty"`
+	NS      []string `json:"ns,omitempty"`
+}
+
+func (r *NameserversResponse) status() (string, string) { return r.Status, r.Message }
+
+// GetNameservers returns the nameservers the domain is delegated to at the
+// registry.
+func (c *Client) GetNameservers(domain string) ([]string, error) {
+	if err := requireDomain(domain); err != nil {
+		return nil, err
+	}
+	authjson, err := c.getAuthJson()
+	if err != nil {
+		return nil, err
+	}
+	var d NameserversResponse
+	if err := c.post(context.Background(), endpoint(PORKBUN_DOMAIN_GET_NS, domain), authjson, &d); err != nil {
+		return nil, err
+	}
+	if d.NS == nil {
+		return []string{}, nil
+	}
+	return d.NS, nil
+}
+
+// UsingPorkbunNameservers reports whether domain is delegated to Porkbun's
+// nameservers only, in which case edits through this package take effect,
+// and returns the current nameservers either way.
+func (c *Client) UsingPorkbunNameservers(domain string) (bool, []string, error) {
+	ns, err := c.GetNameservers(domain)
+	if err != nil {
+		return false, nil, err
+	}
+	if len(ns) == 0 {
+		return false, ns, nil
+	}
+	for _, host := range ns {
+		if !porkbunNameservers[strings.ToLower(strings.TrimSuffix(host, "."))] {
+			return false, ns, nil
+		}
+	}
+	return true, ns, nil
+}
+
+// NameserverChanges compares the delegation of domain with
+// DefaultNameservers and returns the nameservers to add at the registrar
+// and the ones to remove, both empty when the domain already uses Porkbun's
+// nameservers only.
+func (c *Client) NameserverChanges(domain string) (add []string, remove []string, err error) {
+	ns, err := c.GetNameservers(domain)
+	if err != nil {
+		return nil, nil, err
+	}
+	current := make(map[string]bool, len(ns))
+	for _, host := range ns {
+		host = strings.ToLower(strings.TrimSuffix(host, "."))
+		current[host] = true
+		if !porkbunNameservers[host] {
+			remove = append(remove, host)
+		}
+	}
+	for _, host := range DefaultNameservers() {
+		if !current[host] {
+			add = append(add, host)
+		}
+	}
+	return add, remove, nil
+}
@@ -0,0 +1,82 @@
+package porkbun
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNameserverChanges(t *testing.T) {
+	for _, tt := range []struct {
+		ns, add, remove []string
+	}{
+		{
+			ns: []string{"curitiba.ns.porkbun.com", "fortaleza.ns.porkbun.com", "maceio.ns.porkbun.com", "salvador.ns.porkbun.com"},
+		},
+		{
+			ns:     []string{"Maceio.ns.porkbun.com.", "ns1.registrar.example"},
+			add:    []string{"curitiba.ns.porkbun.com", "fortaleza.ns.porkbun.com", "salvador.ns.porkbun.com"},
+			remove: []string{"ns1.registrar.example"},
+		},
+		{
+			add: DefaultNameservers(),
+		},
+	} {
+		body, _ := json.Marshal(NameserversResponse{Status: "SUCCESS", NS: tt.ns})
+		s, c := newTestServer(t, nil, respond(http.StatusOK, string(body)))
+		add, remove, err := c.NameserverChanges("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(add, tt.add) || !reflect.DeepEqual(remove, tt.remove) {
+			t.Errorf("%v: got add %v remove %v, want add %v remove %v", tt.ns, add, remove, tt.add, tt.remove)
+		}
+		if path := s.last(t).Path; path != "/domain/getNs/example.com" {
+			t.Errorf("path = %s", path)
+		}
+	}
+}
+
+func TestListDomainsBadDate(t *testing.T) {
+	_, c := newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS","domains":[
+		{"domain":"example.com","status":"ACTIVE","tld":"com","createDate":"2020-01-02 03:04:05","expireDate":"2026-01-02 03:04:05","securityLock":"1"},
+		{"domain":"example.net","status":"ACTIVE","tld":"net","createDate":"","expireDate":"soon"}]}`))
+	domains, err := c.ListAllDomains(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(domains) != 2 {
+		t.Fatalf("got %d domains, want both", len(domains))
+	}
+	if want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC); !domains[0].Expires.Equal(want) {
+		t.Errorf("Expires = %v, want %v", domains[0].Expires, want)
+	}
+	bad := domains[1]
+	if !bad.Created.IsZero() || !bad.Expires.IsZero() || bad.ExpireDate != "soon" {
+		t.Errorf("bad dates decoded as %+v, want zero times and the raw string kept", bad)
+	}
+}
+
+func TestIsExpiringWithin(t *testing.T) {
+	d := &Domain{Expires: time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)}
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		now    time.Time
+		within time.Duration
+		want   bool
+	}{
+		{now, 31 * 24 * time.Hour, true},
+		{now, 30 * 24 * time.Hour, false},
+		{now.AddDate(0, 2, 0), 0, true},
+	} {
+		if got := d.IsExpiringWithin(tt.now, tt.within); got != tt.want {
+			t.Errorf("IsExpiringWithin(%v, %v) = %t, want %t", tt.now, tt.within, got, tt.want)
+		}
+	}
+	if (&Domain{}).IsExpiringWithin(now, time.Hour) {
+		t.Error("unknown expiry reported as expiring")
+	}
+}
@@ -0,0 +1,199 @@
+package porkbun
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// SPF allows at most this many mechanisms that trigger DNS lookups; receivers
+// fail the check beyond it (RFC 7208, section 4.6.4).
+const SPF_MAX_LOOKUPS = 10
+
+// Policies a DMARC record can ask receivers to apply.
+const (
+	DMARC_POLICY_NONE       = "none"
+	DMARC_POLICY_QUARANTINE = "quarantine"
+	DMARC_POLICY_REJECT     = "reject"
+)
+
+// DMARCPolicy describes a DMARC record. Policy is required; every other field
+// is left out of the record when empty. Report addresses without a scheme
+// get "mailto:" prepended.
+type DMARCPolicy struct {
+	Policy          string
+	SubdomainPolicy string
+	// Percent is the share of failing mail the policy applies to. 0 leaves
+	// pct out, which receivers treat as 100.
+	Percent int
+	// AlignDKIM and AlignSPF are "r" (relaxed) or "s" (strict).
+	AlignDKIM string
+	AlignSPF  string
+	// ReportAggregate and ReportForensic are the rua and ruf addresses.
+	ReportAggregate []string
+	ReportForensic  []string
+}
+
+// NewSPFRecord builds the SPF TXT record of name, relative to the domain
+// ("" for the apex), from mechanisms such as "include:_spf.google.com",
+// "ip4:192.0.2.0/24", "mx" and "-all". Unknown mechanisms, an "all" that is
+// not last and more than SPF_MAX_LOOKUPS lookups are rejected.
+func NewSPFRecord(name string, mechanisms []string) (*DNSRecord, error) {
+	if len(mechanisms) == 0 {
+		return nil, fmt.Errorf("SPF record needs at least one mechanism")
+	}
+	lookups := 0
+	for i, m := range mechanisms {
+		term := strings.ToLower(strings.TrimLeft(m, "+-~?"))
+		key, value := term, ""
+		if j := strings.IndexAny(term, ":=/"); j >= 0 {
+			key, value = term[:j], term[j+1:]
+		}
+		switch key {
+		case "all":
+			if i != len(mechanisms)-1 {
+				return nil, fmt.Errorf("SPF mechanism %q must come last", m)
+			}
+		case "include", "exists", "redirect":
+			if !isHostname(value) {
+				return nil, fmt.Errorf("SPF mechanism %q needs a domain", m)
+			}
+			lookups++
+		case "a", "mx", "ptr":
+			lookups++
+		case "ip4", "ip6":
+			if !isSPFNetwork(value, key == "ip4") {
+				return nil, fmt.Errorf("SPF mechanism %q has an invalid address", m)
+			}
+		case "exp":
+		default:
+			return nil, fmt.Errorf("unknown SPF mechanism %q", m)
+		}
+	}
+	if lookups > SPF_MAX_LOOKUPS {
+		return nil, fmt.Errorf("SPF record needs %d DNS lookups, more than the limit of %d", lookups, SPF_MAX_LOOKUPS)
+	}
+	return &DNSRecord{
+		Name:    name,
+		Type:    "TXT",
+		Content: "v=spf1 " + strings.Join(mechanisms, " "),
+	}, nil
+}
+
+// NewDMARCRecord builds the DMARC TXT record for name, relative to the
+// domain ("" for the apex), which lives at "_dmarc" under it.
+func NewDMARCRecord(name string, policy DMARCPolicy) (*DNSRecord, error) {
+	if !isDMARCPolicy(policy.Policy) {
+		return nil, fmt.Errorf("DMARC policy must be none, quarantine or reject, got %q", policy.Policy)
+	}
+	tags := []string{"v=DMARC1", "p=" + policy.Policy}
+	if policy.SubdomainPolicy != "" {
+		if !isDMARCPolicy(policy.SubdomainPolicy) {
+			return nil, fmt.Errorf("DMARC subdomain policy must be none, quarantine or reject, got %q", policy.SubdomainPolicy)
+		}
+		tags = append(tags, "sp="+policy.SubdomainPolicy)
+	}
+	if policy.Percent != 0 {
+		if policy.Percent < 0 || policy.Percent > 100 {
+			return nil, fmt.Errorf("DMARC percent must be between 0 and 100, got %d", policy.Percent)
+		}
+		tags = append(tags, "pct="+strconv.Itoa(policy.Percent))
+	}
+	for _, align := range []struct{ tag, value string }{{"adkim", policy.AlignDKIM}, {"aspf", policy.AlignSPF}} {
+		if align.value == "" {
+			continue
+		}
+		if align.value != "r" && align.value != "s" {
+			return nil, fmt.Errorf("DMARC %s must be \"r\" or \"s\", got %q", align.tag, align.value)
+		}
+		tags = append(tags, align.tag+"="+align.value)
+	}
+	for _, report := range []struct {
+		tag   string
+		addrs []string
+	}{{"rua", policy.ReportAggregate}, {"ruf", policy.ReportForensic}} {
+		if len(report.addrs) == 0 {
+			continue
+		}
+		uris := make([]string, 0, len(report.addrs))
+		for _, addr := range report.addrs {
+			if !strings.Contains(addr, ":") {
+				addr = "mailto:" + addr
+			}
+			if strings.HasPrefix(addr, "mailto:") && !strings.Contains(addr, "@") {
+				return nil, fmt.Errorf("DMARC %s address %q is not an email address", report.tag, addr)
+			}
+			uris = append(uris, addr)
+		}
+		tags = append(tags, report.tag+"="+strings.Join(uris, ","))
+	}
+	dmarcName := "_dmarc"
+	if name != "" {
+		dmarcName += "." + name
+	}
+	return &DNSRecord{
+		Name:    dmarcName,
+		Type:    "TXT",
+		Content: strings.Join(tags, "; "),
+	}, nil
+}
+
+func isDMARCPolicy(p string) bool {
+	switch p {
+	case DMARC_POLICY_NONE, DMARC_POLICY_QUARANTINE, DMARC_POLICY_REJECT:
+		return true
+	}
+	return false
+}
+
+// isSPFNetwork reports whether s is an address or CIDR network of the given
+// family.
+func isSPFNetwork(s string, ipv4 bool) bool {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		var err error
+		if ip, _, err = net.ParseCIDR(s); err != nil {
+			return false
+		}
+	}
+	return (ip.To4() != nil && !strings.Contains(s, ":")) == ipv4
+}
+
+// EmailToRNAME converts a contact address such as "john.doe@example.com" to
+// the form SOA records carry it in, "john\.doe.example.com.": the "@"
+// becomes a dot and dots in the local part are escaped.
+func EmailToRNAME(email string) (string, error) {
+	at := strings.LastIndexByte(email, '@')
+	if at <= 0 || at == len(email)-1 {
+		return "", fmt.Errorf("%q is not an email address", email)
+	}
+	local, host := email[:at], strings.TrimSuffix(email[at+1:], ".")
+	if strings.ContainsAny(local, " \t\\@") || !isHostname(host) {
+		return "", fmt.Errorf("%q is not an email address", email)
+	}
+	return strings.ReplaceAll(local, ".", `\.`) + "." + host + ".", nil
+}
+
+// RNAMEToEmail converts an SOA contact name back to an email address,
+// treating the first unescaped dot as the "@". The trailing dot is optional.
+func RNAMEToEmail(rname string) (string, error) {
+	rname = strings.TrimSuffix(rname, ".")
+	var local strings.Builder
+	for i := 0; i < len(rname); i++ {
+		switch ch := rname[i]; {
+		case ch == '\\' && i+1 < len(rname):
+			i++
+			local.WriteByte(rname[i])
+		case ch == '.':
+			host := rname[i+1:]
+			if local.Len() == 0 || !isHostname(host) {
+				return "", fmt.Errorf("%q is not a valid RNAME", rname)
+			}
+			return local.String() + "@" + host, nil
+		default:
+			local.WriteByte(ch)
+		}
+	}
+	return "", fmt.Errorf("%q is not a valid RNAME", rname)
+}
@@ -0,0 +1,228 @@
+package porkbun
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// APIError is returned when Porkbun rejects a call, either with a non-200
+// HTTP status or with a status other than SUCCESS in the body. Err holds the
+// matching sentinel, such as ErrRecordExists, when the message is
+// recognised, so errors.Is works on an *APIError.
+type APIError struct {
+	StatusCode int
+	Status     string
+	Message    string
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("porkbun API error (HTTP %d, status %s): %s", e.StatusCode, e.Status, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// newAPIError builds the error for a non-200 response, using the JSON error
+// body when there is one and a snippet of the raw body otherwise. The
+// message is classified before redact is applied to it.
+func newAPIError(statusCode int, body []byte, redact func(string) string) *APIError {
+	var res struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil || res.Status == "" {
+		res.Message = snippet(string(body))
+	}
+	err := &APIError{
+		StatusCode: statusCode,
+		Status:     res.Status,
+		Message:    redact(res.Message),
+		Err:        classifyMessage(res.Message),
+	}
+	if err.Err == nil && statusCode == http.StatusTooManyRequests {
+		err.Err = ErrRateLimited
+	}
+	return err
+}
+
+// snippet returns the start of body, for embedding in error messages.
+func snippet(body string) string {
+	const max = 512
+	if len(body) > max {
+		return body[:max] + "..."
+	}
+	return body
+}
+
+// ErrRecordExists is returned when Porkbun refuses to create a record
+// because an identical one already exists. Importers can treat it as an
+// idempotent success.
+var ErrRecordExists = errors.New("record already exists")
+
+// ErrRecordNotFound is returned when a record looked up by id does not
+// exist.
+var ErrRecordNotFound = errors.New("record not found")
+
+// ErrMultipleRecords is returned by helpers expecting a single record when
+// several match.
+var ErrMultipleRecords = errors.New("multiple records match")
+
+// ErrConflict is returned by the conditional helpers when the live state no
+// longer matches what the caller expected.
+var ErrConflict = errors.New("record changed since it was read")
+
+// ErrRateLimited is returned when Porkbun throttles the client.
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrAPIAccessDisabled is returned when the domain has not been opted in to
+// API access in the Porkbun dashboard.
+var ErrAPIAccessDisabled = errors.New("API access is not enabled for the domain")
+
+// ErrInvalidCredentials is returned when Porkbun rejects the API key or
+// secret.
+var ErrInvalidCredentials = errors.New("invalid API credentials")
+
+// ErrDomainNotActive is returned when the domain is expired or suspended. The
+// owner needs to renew or reactivate it; retrying will not help.
+var ErrDomainNotActive = errors.New("domain is not active")
+
+// ErrResponseTooLarge is returned when a response body exceeds
+// Config.MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response body exceeds the configured size limit")
+
+// ErrZoneFull is returned when a create would take a zone past
+// Config.MaxRecordsPerZone.
+var ErrZoneFull = errors.New("zone record limit reached")
+
+// ErrServiceMaintenance is returned when Porkbun answers with an HTML page
+// instead of JSON, as it does during maintenance. Callers should back off
+// for longer than after an ordinary error.
+var ErrServiceMaintenance = errors.New("porkbun API is unavailable (maintenance page)")
+
+// ErrMalformedResponse is returned when a 200 response is empty or has no
+// status field, which points at a truncated body rather than an API error.
+var ErrMalformedResponse = errors.New("malformed or empty response")
+
+// ErrTypeNotAllowed is returned when a create, edit or delete touches a
+// record type outside Config.AllowedTypes.
+var ErrTypeNotAllowed = errors.New("record type not allowed")
+
+// classifyMessage maps a Porkbun error message to the matching sentinel
+// error, or nil if the message is not recognised.
+func classifyMessage(message string) error {
+	msg := strings.ToLower(message)
+	switch {
+	case strings.Contains(msg, "already exists"), strings.Contains(msg, "duplicate record"):
+		return ErrRecordExists
+	case strings.Contains(msg, "invalid record id"), strings.Contains(msg, "record not found"):
+		return ErrRecordNotFound
+	case strings.Contains(msg, "rate limit"), strings.Contains(msg, "too many requests"):
+		return ErrRateLimited
+	case strings.Contains(msg, "opted in to api access"), strings.Contains(msg, "api access is not enabled"):
+		return ErrAPIAccessDisabled
+	case strings.Contains(msg, "invalid api key"), strings.Contains(msg, "invalid secret"):
+		return ErrInvalidCredentials
+	case containsAny(msg, domainNotActivePhrases):
+		return ErrDomainNotActive
+	}
+	return nil
+}
+
+// domainNotActivePhrases are the ways Porkbun words a domain status
+// problem. They name the domain explicitly so that an expired API key or
+// certificate is not mistaken for an expired domain.
+var domainNotActivePhrases = []string{
+	"domain is not active",
+	"domain is inactive",
+	"domain is expired",
+	"domain has expired",
+	"domain expired",
+	"domain is suspended",
+	"domain has been suspended",
+	"domain suspended",
+}
+
+// containsAny reports whether s contains one of substrs.
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatError renders err as a one-line message for end users of a CLI. For
+// an *APIError it shows Porkbun's message followed by a hint on what to do
+// about it; other errors are returned as is.
+func FormatError(err error) string {
+	if err == nil {
+		return ""
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return err.Error()
+	}
+	msg := apiErr.Message
+	if msg == "" {
+		msg = fmt.Sprintf("request failed with HTTP %d", apiErr.StatusCode)
+	}
+	line := "Porkbun: " + strings.TrimSuffix(msg, ".")
+	if hint := errorHint(apiErr); hint != "" {
+		line += " (" + hint + ")"
+	}
+	return line
+}
+
+func errorHint(err *APIError) string {
+	switch {
+	case errors.Is(err, ErrRateLimited):
+		return "rate limited, wait and retry"
+	case errors.Is(err, ErrInvalidCredentials):
+		return "check the API key and secret API key"
+	case errors.Is(err, ErrAPIAccessDisabled):
+		return "enable API access for the domain in the Porkbun dashboard"
+	case errors.Is(err, ErrDomainNotActive):
+		return "the domain is expired or suspended, renew it"
+	case errors.Is(err, ErrRecordExists):
+		return "the record already exists"
+	case errors.Is(err, ErrRecordNotFound):
+		return "check the record id, it may already be deleted"
+	case err.StatusCode >= 500:
+		return "Porkbun is having trouble, retry later"
+	}
+	return ""
+}
+
+// redact masks sensitive text with Config.Redactor, or by default replaces
+// the API key and secret.
+func (c *Client) redact(s string) string {
+	if c.config.Redactor != nil {
+		return c.config.Redactor(s)
+	}
+	return c.maskSecrets(s)
+}
+
+// sensitiveField matches the JSON fields whose values are secret wherever
+// they appear, such as the private key in an SSL bundle.
+var sensitiveField = regexp.MustCompile(`("privatekey"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+
+// maskSecrets replaces the API key and secret, and the values of sensitive
+// JSON fields such as privatekey, with REDACTED.
+func (c *Client) maskSecrets(s string) string {
+	s = sensitiveField.ReplaceAllString(s, `$1"REDACTED"`)
+	auth := c.auth()
+	var pairs []string
+	for _, secret := range []string{auth.APIKey, auth.SecretAPIKey} {
+		if secret != "" {
+			pairs = append(pairs, secret, "REDACTED")
+		}
+	}
+	return strings.NewReplacer(pairs...).Replace(s)
+}
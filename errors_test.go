@@ -0,0 +1,93 @@
+package porkbun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCreateDuplicateRecord(t *testing.T) {
+	_, c := newTestServer(t, nil, respond(http.StatusBadRequest,
+		`{"status":"ERROR","message":"Create error: We were unable to create the DNS record: duplicate record."}`))
+	_, err := c.CreateRecord("example.com", &DNSRecord{Type: "A", Content: "1.1.1.1"})
+	if !errors.Is(err, ErrRecordExists) {
+		t.Fatalf("got %v, want ErrRecordExists", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("got %#v, want an *APIError with the HTTP status", err)
+	}
+}
+
+func TestClassifyMessage(t *testing.T) {
+	for message, want := range map[string]error{
+		"A record with that name, type and content already exists.": ErrRecordExists,
+		"Duplicate record":                      ErrRecordExists,
+		"Invalid record ID.":                    ErrRecordNotFound,
+		"Invalid API key. (002)":                ErrInvalidCredentials,
+		"Domain is not opted in to API access.": ErrAPIAccessDisabled,
+		"Too many requests, slow down.":         ErrRateLimited,
+		"Something unexpected happened.":        nil,
+	} {
+		if got := classifyMessage(message); got != want {
+			t.Errorf("classifyMessage(%q) = %v, want %v", message, got, want)
+		}
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestErrorsWrapCause(t *testing.T) {
+	errBoom := errors.New("boom")
+	c, err := NewClient(&Config{
+		Auth:   Auth{APIKey: "pk1_test", SecretAPIKey: "sk1_test"},
+		Client: &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) { return nil, errBoom })},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RetrieveRecords("example.com"); !errors.Is(err, errBoom) {
+		t.Errorf("transport failure: got %v, want it to wrap the transport's error", err)
+	}
+
+	ts, c := newTestServer(t, nil, respond(http.StatusOK, `{}`))
+	ts.Close()
+	var opErr *net.OpError
+	if _, err := c.RetrieveRecords("example.com"); !errors.As(err, &opErr) {
+		t.Errorf("closed server: got %v, want a *net.OpError", err)
+	}
+
+	var syntaxErr *json.SyntaxError
+	_, c = newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS","records":[`+"\x00"))
+	if _, err := c.RetrieveRecords("example.com"); !errors.As(err, &syntaxErr) {
+		t.Errorf("invalid JSON: got %v, want a *json.SyntaxError", err)
+	}
+	var typeErr *json.UnmarshalTypeError
+	_, c = newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS","records":{"id":1}}`))
+	if _, err := c.RetrieveRecords("example.com"); !errors.As(err, &typeErr) {
+		t.Errorf("mistyped JSON: got %v, want a *json.UnmarshalTypeError", err)
+	}
+
+	var unsupported *json.UnsupportedTypeError
+	if _, err := c.Do(context.Background(), PORKBUN_DNS_BASE, map[string]interface{}{"c": make(chan int)}); !errors.As(err, &unsupported) {
+		t.Errorf("unmarshalable payload: got %v, want a *json.UnsupportedTypeError", err)
+	}
+}
+
+func TestExpiredDomainError(t *testing.T) {
+	_, c := newTestServer(t, nil, respond(http.StatusBadRequest, `{"status":"ERROR","message":"Unable to edit DNS: the domain has expired."}`))
+	err := c.EditRecord("example.com", "1", &DNSRecord{Type: "A", Content: "1.1.1.1"})
+	if !errors.Is(err, ErrDomainNotActive) {
+		t.Fatalf("got %v, want ErrDomainNotActive", err)
+	}
+	if got := FormatError(err); !strings.Contains(got, "the domain has expired") || !strings.Contains(got, "renew") {
+		t.Errorf("FormatError = %q, want the message and a renewal hint", got)
+	}
+}
@@ -0,0 +1,101 @@
+package porkbun
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// Conditional reads. Porkbun does not document ETag or Last-Modified
+// headers, but when a read response carries one the body is kept, the next
+// read of the same URL sends If-None-Match or If-Modified-Since, and a 304
+// Not Modified answer is served from the kept body as if it had been sent
+// again. Responses without validators are not kept, so against the API as
+// documented every read simply remains a full request.
+
+// cachedResponse is the last successful body of a read and its validators.
+type cachedResponse struct {
+	etag         string
+	lastModified string
+	body         []byte
+}
+
+type conditionalKey struct{}
+
+// withConditional marks ctx as belonging to a read that may be answered
+// from the cache.
+func withConditional(ctx context.Context) context.Context {
+	return context.WithValue(ctx, conditionalKey{}, true)
+}
+
+func isConditional(ctx context.Context) bool {
+	ok, _ := ctx.Value(conditionalKey{}).(bool)
+	return ok
+}
+
+// setConditionalHeaders adds the validators kept for url to req.
+func (c *Client) setConditionalHeaders(ctx context.Context, url string, req *http.Request) {
+	if !isConditional(ctx) {
+		return
+	}
+	c.cacheMu.Lock()
+	cached, ok := c.cache[url]
+	c.cacheMu.Unlock()
+	if !ok {
+		return
+	}
+	if cached.etag != "" {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+	if cached.lastModified != "" {
+		req.Header.Set("If-Modified-Since", cached.lastModified)
+	}
+}
+
+// conditionalResponse turns a 304 answer to a read of url into a 200 one
+// with the kept body. For a 200 answer carrying validators it buffers the
+// body and returns a function keeping it, to be called once the response
+// has been found successful.
+func (c *Client) conditionalResponse(ctx context.Context, url string, res *http.Response) (*http.Response, func()) {
+	if !isConditional(ctx) {
+		return res, nil
+	}
+	switch res.StatusCode {
+	case http.StatusNotModified:
+		c.cacheMu.Lock()
+		cached, ok := c.cache[url]
+		c.cacheMu.Unlock()
+		if !ok {
+			return res, nil
+		}
+		served := *res
+		served.StatusCode = http.StatusOK
+		served.Body = io.NopCloser(bytes.NewReader(cached.body))
+		return &served, nil
+	case http.StatusOK:
+		etag, lastModified := res.Header.Get("ETag"), res.Header.Get("Last-Modified")
+		if etag == "" && lastModified == "" {
+			c.cacheMu.Lock()
+			delete(c.cache, url)
+			c.cacheMu.Unlock()
+			return res, nil
+		}
+		limit := c.maxResponseBytes()
+		body, err := io.ReadAll(io.LimitReader(res.Body, limit+1))
+		buffered := *res
+		buffered.Body = readCloser{io.MultiReader(bytes.NewReader(body), res.Body), res.Body}
+		if err != nil || int64(len(body)) > limit {
+			return &buffered, nil
+		}
+		return &buffered, func() {
+			c.cacheMu.Lock()
+			defer c.cacheMu.Unlock()
+			if c.cache == nil {
+				c.cache = make(map[string]cachedResponse)
+			}
+			c.cache[url] = cachedResponse{etag: etag, lastModified: lastModified, body: body}
+		}
+	}
+	return res, nil
+}
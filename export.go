@@ -0,0 +1,244 @@
+package porkbun
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// MarshalRecords encodes records as a plain JSON array, without
+// credentials, for backups. TTL and Prio keep the string form the API
+// uses, so the result can be decoded with UnmarshalRecords and replayed
+// through CreateRecord.
+func MarshalRecords(records []*DNSRecord) ([]byte, error) {
+	if records == nil {
+		records = []*DNSRecord{}
+	}
+	return json.MarshalIndent(records, "", "  ")
+}
+
+// UnmarshalRecords decodes a JSON array produced by MarshalRecords.
+func UnmarshalRecords(data []byte) ([]*DNSRecord, error) {
+	var records []*DNSRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("decoding records: %w", err)
+	}
+	for i, record := range records {
+		if record == nil {
+			return nil, fmt.Errorf("decoding records: entry %d is null", i)
+		}
+	}
+	if records == nil {
+		records = []*DNSRecord{}
+	}
+	return records, nil
+}
+
+// ExportTerraform writes a porkbun_dns_record resource block for each record,
+// to bootstrap a Terraform configuration from an existing zone. Resource
+// labels are derived from name and type and made unique. It only formats;
+// no API calls are made.
+func ExportTerraform(domain string, records []*DNSRecord, w io.Writer) error {
+	var b strings.Builder
+	labels := make(map[string]int)
+	for i, record := range records {
+		label := terraformLabel(domain, record)
+		if labels[label]++; labels[label] > 1 {
+			label = fmt.Sprintf("%s_%d", label, labels[label])
+		}
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "resource \"porkbun_dns_record\" %q {\n", label)
+		fmt.Fprintf(&b, "  domain  = %s\n", hclString(domain))
+		fmt.Fprintf(&b, "  name    = %s\n", hclString(relativeName(record.Name, domain)))
+		fmt.Fprintf(&b, "  type    = %s\n", hclString(strings.ToUpper(record.Type)))
+		fmt.Fprintf(&b, "  content = %s\n", hclString(record.Content))
+		if record.TTL != "" {
+			fmt.Fprintf(&b, "  ttl     = %s\n", hclString(record.TTL))
+		}
+		if record.Prio != "" {
+			fmt.Fprintf(&b, "  prio    = %s\n", hclString(record.Prio))
+		}
+		if record.Notes != "" {
+			fmt.Fprintf(&b, "  notes   = %s\n", hclString(record.Notes))
+		}
+		b.WriteString("}\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// terraformLabel builds a resource label such as "www_a" or "apex_mx".
+func terraformLabel(domain string, record *DNSRecord) string {
+	name := strings.ToLower(relativeName(record.Name, domain))
+	switch name {
+	case "", "@":
+		name = "apex"
+	case "*":
+		name = "wildcard"
+	}
+	label := make([]byte, 0, len(name)+len(record.Type)+1)
+	for _, ch := range []byte(name + "_" + strings.ToLower(record.Type)) {
+		if ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '_' || ch == '-' {
+			label = append(label, ch)
+		} else {
+			label = append(label, '_')
+		}
+	}
+	if label[0] >= '0' && label[0] <= '9' || label[0] == '-' {
+		label = append([]byte("r_"), label...)
+	}
+	return string(label)
+}
+
+// hclString quotes s as an HCL string literal, escaping interpolation
+// sequences so record content is taken literally.
+func hclString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; ch {
+		case '"', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(ch)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '$', '%':
+			b.WriteByte(ch)
+			if i+1 < len(s) && s[i+1] == '{' {
+				b.WriteByte(ch)
+			}
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// ExportOctoDNS writes records as an OctoDNS zone config, the YAML file
+// octodns-sync reads for domain. Entries are keyed by relative name, empty
+// for the apex, with one entry per type.
+//
+// OctoDNS has one TTL per record set, so the entry takes the first TTL set
+// among its records and the TTLs of the other records are silently
+// discarded. Hostnames gain the trailing dot OctoDNS requires and semicolons
+// in TXT values are escaped. Types OctoDNS cannot represent in its
+// structured form, such as HTTPS and SVCB, are an error. It only formats; no
+// API calls are made.
+func ExportOctoDNS(domain string, records []*DNSRecord, w io.Writer) error {
+	byName := groupByName(records, domain)
+	var b strings.Builder
+	b.WriteString("---\n")
+	for _, name := range sortedKeys(byName) {
+		byType := make(map[string][]*DNSRecord)
+		for _, record := range byName[name] {
+			typ := strings.ToUpper(record.Type)
+			byType[typ] = append(byType[typ], record)
+		}
+		fmt.Fprintf(&b, "'%s':\n", strings.ReplaceAll(name, "'", "''"))
+		for _, typ := range sortedKeys(byType) {
+			fmt.Fprintf(&b, "  - type: %s\n", typ)
+			for _, record := range byType[typ] {
+				if record.TTL != "" && record.TTL != "0" {
+					fmt.Fprintf(&b, "    ttl: %s\n", record.TTL)
+					break
+				}
+			}
+			if typ == "CNAME" || typ == "ALIAS" {
+				fmt.Fprintf(&b, "    value: %s\n", yamlString(octoHost(byType[typ][0].Content)))
+				continue
+			}
+			b.WriteString("    values:\n")
+			for _, record := range byType[typ] {
+				value, err := octoValue(typ, normalizeRecord(*record))
+				if err != nil {
+					return fmt.Errorf("%s record at %q: %w", typ, name, err)
+				}
+				b.WriteString(value)
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// octoValue renders one entry of an OctoDNS values list.
+func octoValue(typ string, record DNSRecord) (string, error) {
+	fields := strings.Fields(record.Content)
+	structured := func(keys ...string) (string, error) {
+		if len(fields) < len(keys) {
+			return "", fmt.Errorf("content %q needs %d fields", record.Content, len(keys))
+		}
+		// The last key takes the rest, e.g. a CAA value with spaces.
+		fields[len(keys)-1] = strings.Join(fields[len(keys)-1:], " ")
+		var b strings.Builder
+		for i, key := range keys {
+			prefix := "        "
+			if i == 0 {
+				prefix = "      - "
+			}
+			value := fields[i]
+			if _, err := strconv.ParseUint(value, 10, 32); err != nil {
+				value = yamlString(value)
+			}
+			fmt.Fprintf(&b, "%s%s: %s\n", prefix, key, value)
+		}
+		return b.String(), nil
+	}
+	switch typ {
+	case "A", "AAAA":
+		return "      - " + yamlString(record.Content) + "\n", nil
+	case "NS":
+		return "      - " + yamlString(octoHost(record.Content)) + "\n", nil
+	case "TXT":
+		return "      - " + yamlString(strings.ReplaceAll(record.Content, ";", `\;`)) + "\n", nil
+	case "MX":
+		fields = []string{prioOrZero(record.Prio), octoHost(record.Content)}
+		return structured("preference", "exchange")
+	case "SRV":
+		if len(fields) != 3 {
+			return "", fmt.Errorf("content %q is not \"weight port target\"", record.Content)
+		}
+		fields = []string{prioOrZero(record.Prio), fields[0], fields[1], octoHost(fields[2])}
+		return structured("priority", "weight", "port", "target")
+	case "CAA":
+		if len(fields) >= 3 {
+			fields[2] = strings.Trim(strings.Join(fields[2:], " "), `"`)
+			fields = fields[:3]
+		}
+		return structured("flags", "tag", "value")
+	case "SSHFP":
+		return structured("algorithm", "fingerprint_type", "fingerprint")
+	case "TLSA":
+		return structured("certificate_usage", "selector", "matching_type", "certificate_association_data")
+	}
+	return "", fmt.Errorf("OctoDNS export does not support %s records", typ)
+}
+
+// prioOrZero returns prio, or "0" when it is unset.
+func prioOrZero(prio string) string {
+	if prio == "" {
+		return "0"
+	}
+	return prio
+}
+
+// octoHost returns host with the trailing dot OctoDNS expects.
+func octoHost(host string) string {
+	return strings.TrimSuffix(host, ".") + "."
+}
+
+// yamlString quotes s as a YAML double-quoted scalar, whose escapes are a
+// superset of JSON's.
+func yamlString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
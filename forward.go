@@ -0,0 +1,149 @@
+package porkbun
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const PORKBUN_DOMAIN_ADD_URL_FORWARD = PORKBUN_DOMAIN_BASE + "/addUrlForward/%s"
+const PORKBUN_DOMAIN_GET_URL_FORWARDING = PORKBUN_DOMAIN_BASE + "/getUrlForwarding/%s"
+const PORKBUN_DOMAIN_DELETE_URL_FORWARD = PORKBUN_DOMAIN_BASE + "/deleteUrlForward/%s/%s"
+
+// Forward types accepted in URLForward.Type.
+const (
+	URL_FORWARD_TEMPORARY = "temporary"
+	URL_FORWARD_PERMANENT = "permanent"
+)
+
+// URLForward is a URL redirect of a domain or one of its subdomains, e.g.
+//
+//	{"id":"22049209","subdomain":"","location":"https://example.org",
+//	 "type":"temporary","includePath":"no","wildcard":"yes"}
+//
+// IncludePath and Wildcard are "yes" or "no". ID is assigned by Porkbun and
+// ignored when adding a forward.
+type URLForward struct {
+	ID          string `json:"id,omitempty"`
+	Subdomain   string `json:"subdomain"`
+	Location    string `json:"location,omitempty"`
+	Type        string `json:"type,omitempty"`
+	IncludePath string `json:"includePath,omitempty"`
+	Wildcard    string `json:"wildcard,omitempty"`
+}
+
+type URLForwardResponse struct {
+	Status   string        `json:"status,omitempty"`
+	Message  string        `json:"message,omitempty"`
+	Forwards []*URLForward `json:"forwards,omitempty"`
+}
+
+func (r *URLForwardResponse) status() (string, string) { return r.Status, r.Message }
+
+// GetURLForwards returns the URL forwards of domain.
+func (c *Client) GetURLForwards(domain string) ([]*URLForward, error) {
+	if err := requireDomain(domain); err != nil {
+		return nil, err
+	}
+	authjson, err := c.getAuthJson()
+	if err != nil {
+		return nil, err
+	}
+	var d URLForwardResponse
+	if err := c.post(context.Background(), endpoint(PORKBUN_DOMAIN_GET_URL_FORWARDING, domain), authjson, &d); err != nil {
+		return nil, err
+	}
+	if d.Forwards == nil {
+		return []*URLForward{}, nil
+	}
+	return d.Forwards, nil
+}
+
+// AddURLForward adds a URL forward to domain. The API does not return the
+// new forward's ID; use GetURLForwards to find it.
+func (c *Client) AddURLForward(domain string, forward *URLForward) error {
+	if err := requireDomain(domain); err != nil {
+		return err
+	}
+	if forward.Location == "" {
+		return fmt.Errorf("forward location must not be empty")
+	}
+	payload := *forward
+	payload.ID = ""
+	authjson, err := c.getPayloadWithAuthJson(payload)
+	if err != nil {
+		return err
+	}
+	return c.postWrite(context.Background(), endpoint(PORKBUN_DOMAIN_ADD_URL_FORWARD, domain), authjson, &DNSResponse{}, false)
+}
+
+// DeleteURLForward deletes the URL forward with the given id.
+func (c *Client) DeleteURLForward(domain string, id string) error {
+	if err := requireDomainAndID(domain, id); err != nil {
+		return err
+	}
+	authjson, err := c.getAuthJson()
+	if err != nil {
+		return err
+	}
+	return c.postWrite(context.Background(), endpoint(PORKBUN_DOMAIN_DELETE_URL_FORWARD, domain, id), authjson, &DNSResponse{}, true)
+}
+
+// ReplaceURLForwards makes forwards the complete set of URL forwards of
+// domain. Existing forwards equal to a desired one are kept, the other
+// desired forwards are added, and only then are the stale existing ones
+// deleted, so redirects never go missing. The results list the adds, which
+// carry no ID since the API does not return one, followed by the deletes,
+// by ID. Every desired forward is validated first; when that or an add
+// fails, nothing is deleted and the error reports it alongside the results
+// so far. Otherwise the error is only set when the existing forwards cannot
+// be retrieved.
+func (c *Client) ReplaceURLForwards(domain string, forwards []*URLForward) ([]BulkResult, error) {
+	for _, forward := range forwards {
+		if forward.Location == "" {
+			return nil, fmt.Errorf("forward for %q: location must not be empty", forward.Subdomain)
+		}
+	}
+	existing, err := c.GetURLForwards(domain)
+	if err != nil {
+		return nil, err
+	}
+	kept := make(map[string]bool, len(existing))
+	results := make([]BulkResult, 0, len(existing)+len(forwards))
+	for _, forward := range forwards {
+		if current := matchURLForward(existing, kept, forward); current != nil {
+			kept[current.ID] = true
+			continue
+		}
+		err := c.AddURLForward(domain, forward)
+		results = append(results, BulkResult{Err: err})
+		if err != nil {
+			return results, fmt.Errorf("adding the forward for %q, existing forwards left in place: %w", forward.Subdomain, err)
+		}
+	}
+	for _, forward := range existing {
+		if !kept[forward.ID] {
+			results = append(results, BulkResult{ID: forward.ID, Err: c.DeleteURLForward(domain, forward.ID)})
+		}
+	}
+	return results, nil
+}
+
+// matchURLForward returns the first forward of existing not yet kept that
+// redirects like forward, or nil.
+func matchURLForward(existing []*URLForward, kept map[string]bool, forward *URLForward) *URLForward {
+	for _, current := range existing {
+		if !kept[current.ID] && sameURLForward(current, forward) {
+			return current
+		}
+	}
+	return nil
+}
+
+// sameURLForward reports whether a and b redirect the same way, ignoring
+// their IDs.
+func sameURLForward(a *URLForward, b *URLForward) bool {
+	return strings.EqualFold(a.Subdomain, b.Subdomain) && a.Location == b.Location &&
+		strings.EqualFold(a.Type, b.Type) && strings.EqualFold(a.IncludePath, b.IncludePath) &&
+		strings.EqualFold(a.Wildcard, b.Wildcard)
+}
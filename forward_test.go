@@ -0,0 +1,72 @@
+package porkbun
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const forwardsPayload = `{"status":"SUCCESS","forwards":[
+	{"id":"1","subdomain":"","location":"https://example.org","type":"permanent","includePath":"no","wildcard":"yes"},
+	{"id":"2","subdomain":"old","location":"https://old.example.org","type":"temporary","includePath":"no","wildcard":"no"}]}`
+
+func TestReplaceURLForwards(t *testing.T) {
+	for _, addFails := range []bool{false, true} {
+		s, c := newTestServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case strings.HasPrefix(r.URL.Path, "/domain/getUrlForwarding/"):
+				respond(http.StatusOK, forwardsPayload)(w, r)
+			case strings.HasPrefix(r.URL.Path, "/domain/addUrlForward/") && addFails:
+				respond(http.StatusBadRequest, `{"status":"ERROR","message":"Invalid location."}`)(w, r)
+			default:
+				respond(http.StatusOK, `{"status":"SUCCESS"}`)(w, r)
+			}
+		})
+		existing, err := c.GetURLForwards("example.com")
+		if err != nil {
+			t.Fatal(err)
+		}
+		desired := []*URLForward{
+			existing[0],
+			{Subdomain: "new", Location: "https://new.example.org", Type: URL_FORWARD_TEMPORARY, IncludePath: "no", Wildcard: "no"},
+		}
+		results, err := c.ReplaceURLForwards("example.com", desired)
+
+		var paths []string
+		for _, req := range s.Requests()[2:] {
+			paths = append(paths, req.Path)
+		}
+		if addFails {
+			if err == nil || len(results) != 1 || results[0].Err == nil {
+				t.Errorf("failed add: got %+v, %v, want one failed result and an error", results, err)
+			}
+			if strings.Join(paths, " ") != "/domain/addUrlForward/example.com" {
+				t.Errorf("failed add: sent %v, want no deletes", paths)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 2 || results[0].ID != "" || results[1].ID != "2" {
+			t.Errorf("results = %+v, want the add then the delete of 2", results)
+		}
+		if strings.Join(paths, " ") != "/domain/addUrlForward/example.com /domain/deleteUrlForward/example.com/2" {
+			t.Errorf("sent %v, want the add before the delete and forward 1 kept", paths)
+		}
+	}
+}
+
+func TestReplaceURLForwardsValidatesFirst(t *testing.T) {
+	s, c := newTestServer(t, nil, respond(http.StatusOK, forwardsPayload))
+	_, err := c.ReplaceURLForwards("example.com", []*URLForward{
+		{Subdomain: "a", Location: "https://a.example.org"},
+		{Subdomain: "b"},
+	})
+	if err == nil {
+		t.Fatal("got no error for a forward without a location")
+	}
+	if n := len(s.Requests()); n != 0 {
+		t.Errorf("%d requests sent before validation failed", n)
+	}
+}
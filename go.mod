@@ -1,3 +1,8 @@
 module github.com/blmhemu/porkbun-go
 
 go 1.16
+
+require (
+	golang.org/x/net v0.0.0-20210226172049-e18ecbb05110
+	golang.org/x/text v0.3.6 // indirect
+)
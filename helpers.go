@@ -0,0 +1,565 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// SetRecordNotes replaces the notes of a record, resubmitting every other
+// field unchanged. Empty notes clear them; EditRecord leaves out empty
+// notes and cannot.
+func (c *Client) SetRecordNotes(domain string, id string, notes string) error {
+	record, err := c.RetrieveRecord(domain, id)
+	if err != nil {
+		return err
+	}
+	record.Notes = notes
+	return c.editRecordNotes(context.Background(), domain, id, record, true)
+}
+
+// ClearRecordNotes removes the notes of a record.
+func (c *Client) ClearRecordNotes(domain string, id string) error {
+	return c.SetRecordNotes(domain, id, "")
+}
+
+// RetrieveRecordsByID retrieves the zone and indexes its records by ID, for
+// reconcilers that look records up repeatedly.
+func (c *Client) RetrieveRecordsByID(domain string) (map[string]*DNSRecord, error) {
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*DNSRecord, len(records))
+	for _, record := range records {
+		byID[record.ID] = record
+	}
+	return byID, nil
+}
+
+// EditRecordIfUnchanged edits the record only if its live name, type,
+// content, TTL and prio still equal those of expected, giving optimistic
+// concurrency between tools sharing a zone. Otherwise it returns an error
+// wrapping ErrConflict. Another writer may still slip in between the check
+// and the edit, as the API has no conditional update.
+func (c *Client) EditRecordIfUnchanged(domain string, id string, expected *DNSRecord, desired *DNSRecord) error {
+	current, err := c.RetrieveRecord(domain, id)
+	if err != nil {
+		return err
+	}
+	if !sameRecord(domain, current, expected) {
+		return fmt.Errorf("%w: %s record %s in %s", ErrConflict, current.Type, id, domain)
+	}
+	return c.EditRecord(domain, id, desired)
+}
+
+// DEFAULT_RECORD_TYPE is the type NewRecord uses when
+// Config.DefaultRecordType is unset.
+const DEFAULT_RECORD_TYPE = "A"
+
+// NewRecord returns a record named name with content and the client's
+// default type, Config.DefaultRecordType or DEFAULT_RECORD_TYPE, for tools
+// that mostly manage one type, e.g. a dynamic DNS updater. Set Type on the
+// returned record to override it for that record.
+func (c *Client) NewRecord(name string, content string) *DNSRecord {
+	typ := c.config.DefaultRecordType
+	if typ == "" {
+		typ = DEFAULT_RECORD_TYPE
+	}
+	return &DNSRecord{Name: name, Type: strings.ToUpper(typ), Content: content}
+}
+
+// AssertRecord checks that a record of recordType at subdomain currently has
+// expectedContent, for monitoring probes. With several records at the name,
+// one of them must match. It returns an error wrapping ErrRecordNotFound when
+// there is no such record, and an error listing the actual content when none
+// matches.
+func (c *Client) AssertRecord(domain string, recordType string, subdomain string, expectedContent string) error {
+	records, err := c.RetrieveRecordsByNameType(domain, recordType, subdomain)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("%w: no %s record at %q in %s", ErrRecordNotFound, recordType, subdomain, domain)
+	}
+	want := normalizeRecord(DNSRecord{Type: recordType, Content: expectedContent}).Content
+	actual := make([]string, 0, len(records))
+	for _, record := range records {
+		have := normalizeRecord(*record).Content
+		if have == want {
+			return nil
+		}
+		actual = append(actual, strconv.Quote(have))
+	}
+	return fmt.Errorf("%s record at %q in %s: expected %q, got %s", recordType, subdomain, domain, want, strings.Join(actual, ", "))
+}
+
+// AssertMXPriority checks that an MX record at subdomain currently has the
+// expected priority, for monitoring that edits have not reset it. With
+// several MX records at the name, one of them must match. It returns an
+// error wrapping ErrRecordNotFound when there is no MX record, and an error
+// listing the actual priorities when none matches.
+func (c *Client) AssertMXPriority(domain string, subdomain string, expected uint16) error {
+	records, err := c.RetrieveRecordsByNameType(domain, "MX", subdomain)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("%w: no MX record at %q in %s", ErrRecordNotFound, subdomain, domain)
+	}
+	actual := make([]string, 0, len(records))
+	for _, record := range records {
+		if prio, err := strconv.ParseUint(record.Prio, 10, 16); err == nil && uint16(prio) == expected {
+			return nil
+		}
+		actual = append(actual, strconv.Quote(record.Prio))
+	}
+	return fmt.Errorf("MX record at %q in %s: expected priority %d, got %s", subdomain, domain, expected, strings.Join(actual, ", "))
+}
+
+// CloneRecord copies a record to newName, a subdomain like "staging" or ""
+// for the apex, keeping its type, content, TTL, prio and notes. It returns
+// the ID of the new record.
+func (c *Client) CloneRecord(domain string, id string, newName string) (string, error) {
+	record, err := c.RetrieveRecord(domain, id)
+	if err != nil {
+		return "", err
+	}
+	record.Name = newName
+	return c.CreateRecord(domain, record)
+}
+
+// CreateAndVerifyRecord creates record and retrieves it back by the returned
+// ID, giving the record as Porkbun stored it, with its ID, fully qualified
+// name and default TTL filled in.
+func (c *Client) CreateAndVerifyRecord(domain string, record *DNSRecord) (*DNSRecord, error) {
+	id, err := c.CreateRecord(domain, record)
+	if err != nil {
+		return nil, err
+	}
+	created, err := c.RetrieveRecord(domain, id)
+	if err != nil {
+		return nil, fmt.Errorf("verifying created record %s: %w", id, err)
+	}
+	return created, nil
+}
+
+// DeleteAndVerifyRecord deletes the record with the given ID and retrieves
+// it by ID afterwards, for cleanups that need positive confirmation such as
+// removing a leaked secret. It fails if the record is still there or its
+// absence cannot be confirmed.
+func (c *Client) DeleteAndVerifyRecord(domain string, id string) error {
+	if err := c.DeleteRecord(domain, id); err != nil {
+		return err
+	}
+	_, err := c.RetrieveRecord(domain, id)
+	switch {
+	case errors.Is(err, ErrRecordNotFound):
+		return nil
+	case err != nil:
+		return fmt.Errorf("verifying deleted record %s: %w", id, err)
+	}
+	return fmt.Errorf("verifying deleted record %s: record still exists in %s", id, domain)
+}
+
+// MatchSelector picks the record a single-result helper such as
+// GetRecordContent or UpsertRecord acts on when several records share the
+// name and type, as in a round-robin set, or returns an error to refuse.
+type MatchSelector func(records []*DNSRecord) (*DNSRecord, error)
+
+// ErrorOnMultiple is the default MatchSelector: it refuses with an error
+// wrapping ErrMultipleRecords.
+func ErrorOnMultiple(records []*DNSRecord) (*DNSRecord, error) {
+	return nil, fmt.Errorf("%w: %d records match", ErrMultipleRecords, len(records))
+}
+
+// FirstMatch is a MatchSelector picking the first record in API order.
+func FirstMatch(records []*DNSRecord) (*DNSRecord, error) {
+	return records[0], nil
+}
+
+// SelectFirstWhere returns a MatchSelector picking the first record for
+// which pred returns true, refusing like ErrorOnMultiple when there is none.
+func SelectFirstWhere(pred func(*DNSRecord) bool) MatchSelector {
+	return func(records []*DNSRecord) (*DNSRecord, error) {
+		for _, record := range records {
+			if pred(record) {
+				return record, nil
+			}
+		}
+		return nil, fmt.Errorf("%w: none of %d records is selected", ErrMultipleRecords, len(records))
+	}
+}
+
+// selectRecord returns the only one of records, or the one picked by
+// Config.MultipleMatch when there are several. records must not be empty.
+func (c *Client) selectRecord(records []*DNSRecord) (*DNSRecord, error) {
+	if len(records) == 1 {
+		return records[0], nil
+	}
+	selector := c.config.MultipleMatch
+	if selector == nil {
+		selector = ErrorOnMultiple
+	}
+	return selector(records)
+}
+
+// GetRecordContent returns the content of the single record of recordType
+// at subdomain, e.g. the current IP of a dynamic DNS name. It returns an
+// error wrapping ErrRecordNotFound when there is no such record; with
+// several, Config.MultipleMatch decides, by default returning an error
+// wrapping ErrMultipleRecords.
+func (c *Client) GetRecordContent(domain string, recordType string, subdomain string) (string, error) {
+	records, err := c.RetrieveRecordsByNameType(domain, recordType, subdomain)
+	if err != nil {
+		return "", err
+	}
+	if len(records) == 0 {
+		return "", fmt.Errorf("%w: no %s record at %q in %s", ErrRecordNotFound, recordType, subdomain, domain)
+	}
+	record, err := c.selectRecord(records)
+	if err != nil {
+		return "", fmt.Errorf("%s records at %q in %s: %w", recordType, subdomain, domain, err)
+	}
+	return record.Content, nil
+}
+
+// GetTXTRecords returns the content of the TXT records at subdomain, e.g.
+// to check a domain verification token, unquoted and in API order. A name
+// without TXT records yields an empty slice.
+func (c *Client) GetTXTRecords(domain string, subdomain string) ([]string, error) {
+	records, err := c.RetrieveRecordsByNameType(domain, "TXT", subdomain)
+	if err != nil {
+		return nil, err
+	}
+	contents := make([]string, 0, len(records))
+	for _, record := range records {
+		contents = append(contents, record.Content)
+	}
+	return contents, nil
+}
+
+// DeleteRecordIfExists deletes the record with the given id, reporting
+// whether it existed. A record that is already gone is not an error, so
+// teardown scripts can run repeatedly.
+func (c *Client) DeleteRecordIfExists(domain string, id string) (bool, error) {
+	err := c.DeleteRecord(domain, id)
+	if errors.Is(err, ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// EffectiveTTL returns the TTL in seconds Porkbun applied to a record, which
+// for a record created without a TTL is the server's default. Use
+// CreateAndVerifyRecord to get it together with the rest of a new record.
+func (c *Client) EffectiveTTL(domain string, id string) (int, error) {
+	record, err := c.RetrieveRecord(domain, id)
+	if err != nil {
+		return 0, err
+	}
+	ttl, err := strconv.Atoi(record.TTL)
+	if err != nil {
+		return 0, fmt.Errorf("record %s in %s has an invalid TTL %q", id, domain, record.TTL)
+	}
+	return ttl, nil
+}
+
+// NextFreeSubdomain returns the first name prefix1, prefix2, ... not used by
+// any record of domain, such as "pr-3" for preview environments when "pr-1"
+// and "pr-2" exist. A name counts as used when a record has it or lives
+// below it.
+func (c *Client) NextFreeSubdomain(domain string, prefix string) (string, error) {
+	if prefix == "" {
+		return "", fmt.Errorf("prefix must not be empty")
+	}
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return "", err
+	}
+	used := make(map[string]bool)
+	for _, record := range records {
+		labels := strings.Split(nameKey(record.Name, domain), ".")
+		used[labels[len(labels)-1]] = true
+	}
+	prefix = strings.ToLower(prefix)
+	for n := 1; ; n++ {
+		if name := prefix + strconv.Itoa(n); !used[name] {
+			return name, nil
+		}
+	}
+}
+
+// UpsertRecord makes record the only record of its type at its name: when
+// none exists it is created, otherwise the existing one is edited to match,
+// unless it already does. When several exist, Config.MultipleMatch picks
+// the one to keep, by default refusing with an error wrapping
+// ErrMultipleRecords, and the others at that name and type are deleted. It
+// returns the ID of the resulting record.
+func (c *Client) UpsertRecord(domain string, record *DNSRecord) (string, error) {
+	name := relativeName(record.Name, domain)
+	existing, err := c.RetrieveRecordsByNameType(domain, record.Type, name)
+	if err != nil {
+		return "", err
+	}
+	if len(existing) == 0 {
+		return c.CreateRecord(domain, record)
+	}
+	keep, err := c.selectRecord(existing)
+	if err != nil {
+		return "", fmt.Errorf("%s records at %q in %s: %w", record.Type, name, domain, err)
+	}
+	if normalizeRecord(*keep).Content != normalizeRecord(*record).Content || !recordSatisfies(keep, record, DiffOptions{}) {
+		if err := c.EditRecord(domain, keep.ID, record); err != nil {
+			return "", err
+		}
+	}
+	for _, extra := range existing {
+		if extra == keep {
+			continue
+		}
+		if err := c.DeleteRecord(domain, extra.ID); err != nil {
+			return keep.ID, err
+		}
+	}
+	return keep.ID, nil
+}
+
+// SetDualStack points subdomain at ipv4 and ipv6 by upserting its A and AAAA
+// records, the usual dynamic DNS update of a dual-stack host. An empty
+// address leaves that family alone, and a ttl of 0 uses Porkbun's default.
+// Both updates are attempted and reported in order; the error is only set
+// when an address is invalid.
+func (c *Client) SetDualStack(domain string, subdomain string, ipv4 string, ipv6 string, ttl int) ([]BulkResult, error) {
+	var records []*DNSRecord
+	for _, family := range []struct{ typ, addr string }{{"A", ipv4}, {"AAAA", ipv6}} {
+		if family.addr == "" {
+			continue
+		}
+		record := &DNSRecord{Name: subdomain, Type: family.typ, Content: family.addr}
+		if ttl > 0 {
+			record.TTL = strconv.Itoa(ttl)
+		}
+		if err := record.Validate(); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	results := make([]BulkResult, 0, len(records))
+	for _, record := range records {
+		id, err := c.UpsertRecord(domain, record)
+		results = append(results, BulkResult{ID: id, Record: record, Err: err})
+	}
+	return results, nil
+}
+
+// DEFAULT_LOOKUP_TIMEOUT bounds the hostname lookups of helpers such as
+// SetApexALIAS when their context has no deadline.
+const DEFAULT_LOOKUP_TIMEOUT = 5 * time.Second
+
+// SetApexALIAS points the apex of domain at target, a hostname such as a
+// load balancer's, by upserting its ALIAS record, flattened by Porkbun into
+// the target's addresses. A ttl of 0 uses Porkbun's default. It refuses,
+// before any change, targets that are not valid hostnames, that are domain
+// itself, or that do not resolve to an address now, and apex A, AAAA or
+// CNAME records, which would conflict with the ALIAS; delete those first.
+// It returns the ID of the ALIAS record.
+func (c *Client) SetApexALIAS(domain string, target string, ttl int) (string, error) {
+	return c.SetApexALIASContext(context.Background(), domain, target, ttl)
+}
+
+// SetApexALIASContext is SetApexALIAS with a context for the lookup of
+// target, done with Config.Resolver, and the retrieve of the zone. Without a
+// deadline the lookup gives up after DEFAULT_LOOKUP_TIMEOUT.
+func (c *Client) SetApexALIASContext(ctx context.Context, domain string, target string, ttl int) (string, error) {
+	target = strings.TrimSuffix(strings.TrimSpace(target), ".")
+	if !isHostname(target) {
+		return "", fmt.Errorf("ALIAS target %q is not a valid hostname", target)
+	}
+	if strings.EqualFold(target, strings.TrimSuffix(domain, ".")) {
+		return "", fmt.Errorf("ALIAS target %q is the domain itself", target)
+	}
+	lookupCtx, cancel := withTimeout(ctx, DEFAULT_LOOKUP_TIMEOUT)
+	addrs, err := c.resolver().LookupHost(lookupCtx, asciiName(target))
+	cancel()
+	if err != nil {
+		return "", fmt.Errorf("ALIAS target %q does not resolve: %w", target, err)
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("ALIAS target %q does not resolve to an address", target)
+	}
+	records, err := c.retrieveRecords(ctx, domain)
+	if err != nil {
+		return "", err
+	}
+	for _, record := range records {
+		switch typ := strings.ToUpper(record.Type); typ {
+		case "A", "AAAA", "CNAME":
+			if nameKey(record.Name, domain) == "" {
+				return "", fmt.Errorf("the apex of %s has a %s record (%s), which conflicts with an ALIAS", domain, typ, record.Content)
+			}
+		}
+	}
+	record := &DNSRecord{Name: "", Type: "ALIAS", Content: target}
+	if ttl > 0 {
+		record.TTL = strconv.Itoa(ttl)
+	}
+	return c.UpsertRecord(domain, record)
+}
+
+// resolver returns Config.Resolver, or net.DefaultResolver when unset.
+func (c *Client) resolver() *net.Resolver {
+	if c.config.Resolver != nil {
+		return c.config.Resolver
+	}
+	return net.DefaultResolver
+}
+
+// GetApexALIAS returns the target of the ALIAS record at the apex of domain,
+// or an error wrapping ErrRecordNotFound when the apex has none.
+func (c *Client) GetApexALIAS(domain string) (string, error) {
+	return c.GetRecordContent(domain, "ALIAS", "")
+}
+
+// RecordTypesInZone returns the distinct record types present in domain,
+// sorted, e.g. to build type filters.
+func (c *Client) RecordTypesInZone(domain string) ([]string, error) {
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	types := []string{}
+	for _, record := range records {
+		typ := strings.ToUpper(record.Type)
+		if !seen[typ] {
+			seen[typ] = true
+			types = append(types, typ)
+		}
+	}
+	sort.Strings(types)
+	return types, nil
+}
+
+// FindRecordsReferencing returns the records of domain pointing at target,
+// an IP address or hostname, e.g. before decommissioning a server: A and
+// AAAA records with that address, CNAME, ALIAS, MX and NS records with that
+// host, SRV records targeting it, and TXT records mentioning it as a whole
+// token, such as an SPF "ip4:1.2.3.4" or "include:" mechanism. Substrings do
+// not count, so "1.2.3.4" does not match "ip4:1.2.3.45".
+func (c *Client) FindRecordsReferencing(domain string, target string) ([]*DNSRecord, error) {
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	ip := net.ParseIP(target)
+	host := strings.ToLower(strings.TrimSuffix(target, "."))
+	sameHost := func(s string) bool {
+		return strings.EqualFold(strings.TrimSuffix(s, "."), host)
+	}
+	matches := []*DNSRecord{}
+	for _, record := range records {
+		var match bool
+		switch strings.ToUpper(record.Type) {
+		case "A", "AAAA":
+			match = ip != nil && ip.Equal(net.ParseIP(record.Content))
+		case "CNAME", "ALIAS", "MX", "NS":
+			match = sameHost(record.Content)
+		case "SRV":
+			fields := strings.Fields(record.Content)
+			match = len(fields) > 0 && sameHost(fields[len(fields)-1])
+		case "TXT":
+			for _, token := range txtTokens(record.Content) {
+				if sameHost(token) || (ip != nil && ip.Equal(net.ParseIP(token))) {
+					match = true
+					break
+				}
+			}
+		}
+		if match {
+			matches = append(matches, record)
+		}
+	}
+	return matches, nil
+}
+
+// txtTokens splits TXT content into the values it may reference: words
+// separated by whitespace and the delimiters of SPF, DMARC and similar
+// policies, without SPF qualifiers, mechanism names and URI schemes such as
+// "ip4:" or "mailto:", CIDR suffixes, paths and user parts. IPv6 addresses
+// stay whole.
+func txtTokens(content string) []string {
+	words := strings.FieldsFunc(content, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune(`;,="'`, r)
+	})
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		word = strings.TrimLeft(word, "+-~?")
+		if i := strings.LastIndexByte(word, '/'); i > 0 && isDigits(word[i+1:]) {
+			word = word[:i]
+		}
+		if net.ParseIP(word) == nil {
+			if i := strings.IndexByte(word, ':'); i > 0 && isMechanismName(word[:i]) {
+				word = strings.TrimPrefix(word[i+1:], "//")
+			}
+			if i := strings.IndexByte(word, '/'); i >= 0 {
+				word = word[:i]
+			}
+			if i := strings.LastIndexByte(word, '@'); i >= 0 {
+				word = word[i+1:]
+			}
+		}
+		if word != "" {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isMechanismName reports whether s is the name before the colon of an SPF
+// mechanism or a URI scheme: a letter followed by letters and digits.
+func isMechanismName(s string) bool {
+	for i, r := range s {
+		letter := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+		if !letter && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// RetrieveRecordsMatchingName retrieves the zone once and returns the records
+// whose name relative to domain, "" for the apex, matches pattern, e.g.
+// regexp.MustCompile(`^test-`). Anchoring is up to the pattern.
+func (c *Client) RetrieveRecordsMatchingName(domain string, pattern *regexp.Regexp) ([]*DNSRecord, error) {
+	records, err := c.RetrieveRecords(domain)
+	if err != nil {
+		return nil, err
+	}
+	matches := []*DNSRecord{}
+	for _, record := range records {
+		if pattern.MatchString(relativeName(record.Name, domain)) {
+			matches = append(matches, record)
+		}
+	}
+	return matches, nil
+}
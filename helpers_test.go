@@ -0,0 +1,136 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindRecordsReferencingTokens(t *testing.T) {
+	zone := newFakeZone("example.com",
+		&DNSRecord{Name: "", Type: "TXT", Content: "v=spf1 ip4:1.2.3.4 -all"},
+		&DNSRecord{Name: "", Type: "TXT", Content: "v=spf1 ip4:1.2.3.4/32 include:mail.example.net ~all"},
+		&DNSRecord{Name: "", Type: "TXT", Content: "v=spf1 ip4:1.2.3.45 ip4:11.2.3.4 -all"},
+		&DNSRecord{Name: "_dmarc", Type: "TXT", Content: "v=DMARC1; p=none; rua=mailto:dmarc@mail.example.net"},
+		&DNSRecord{Name: "old", Type: "TXT", Content: "moved from smtp.mail.example.net"},
+		&DNSRecord{Name: "v6", Type: "TXT", Content: "v=spf1 ip6:2001:db8::1 -all"},
+		&DNSRecord{Name: "www", Type: "A", Content: "1.2.3.4"},
+		&DNSRecord{Name: "www2", Type: "A", Content: "1.2.3.40"},
+	)
+	_, c := newTestServer(t, nil, zone.ServeHTTP)
+	for target, want := range map[string][]string{
+		"1.2.3.4":          {"1001", "1002", "1007"},
+		"mail.example.net": {"1002", "1004"},
+		"2001:db8:0::1":    {"1006"},
+	} {
+		records, err := c.FindRecordsReferencing("example.com", target)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, record := range records {
+			ids = append(ids, record.ID)
+		}
+		if !reflect.DeepEqual(ids, want) {
+			t.Errorf("%s: got %v, want %v", target, ids, want)
+		}
+	}
+}
+
+func TestTXTTokens(t *testing.T) {
+	got := txtTokens(`v=spf1 +ip4:192.0.2.0/24 ~include:_spf.example.com ip6:2001:db8::/32 -all "rua=mailto:a@example.org" https://example.net/x`)
+	want := []string{"v", "spf1", "192.0.2.0", "_spf.example.com", "2001:db8::", "all", "rua", "example.org", "example.net"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestClearRecordNotes(t *testing.T) {
+	zone := newFakeZone("example.com",
+		&DNSRecord{Name: "www", Type: "A", Content: "1.1.1.1", TTL: "600", Notes: "web"},
+	)
+	s, c := newTestServer(t, nil, zone.ServeHTTP)
+	if err := c.ClearRecordNotes("example.com", "1001"); err != nil {
+		t.Fatal(err)
+	}
+	edit := s.last(t)
+	if notes, ok := edit.field(t, "notes"); !ok || notes != "" {
+		t.Errorf("notes = %v (present %t), want an explicit empty string", notes, ok)
+	}
+	if content, _ := edit.field(t, "content"); content != "1.1.1.1" {
+		t.Errorf("content = %v, want 1.1.1.1", content)
+	}
+	records := zone.Records()
+	if len(records) != 1 || records[0].Notes != "" {
+		t.Errorf("records = %+v, want the note cleared", records)
+	}
+}
+
+func TestEditMXKeepsPriority(t *testing.T) {
+	zone := newFakeZone("example.com",
+		&DNSRecord{Name: "", Type: "MX", Content: "mx1.example.com", Prio: "10"},
+	)
+	s, c := newTestServer(t, nil, zone.ServeHTTP)
+	if err := c.EditRecord("example.com", "1001", &DNSRecord{Type: "MX", Content: "mx2.example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if prio, _ := s.last(t).field(t, "prio"); prio != "10" {
+		t.Errorf("edit prio = %v, want the current 10", prio)
+	}
+	if err := c.AssertMXPriority("example.com", "", 10); err != nil {
+		t.Errorf("AssertMXPriority 10: %v", err)
+	}
+	if err := c.AssertMXPriority("example.com", "", 20); err == nil || !strings.Contains(err.Error(), `"10"`) {
+		t.Errorf("AssertMXPriority 20: got %v, want an error listing priority 10", err)
+	}
+	if err := c.AssertMXPriority("example.com", "mail", 10); !errors.Is(err, ErrRecordNotFound) {
+		t.Errorf("AssertMXPriority on an empty name: got %v, want ErrRecordNotFound", err)
+	}
+}
+
+// addressResolver returns a resolver answering every A query with ip and
+// every other query with no records.
+func addressResolver(t *testing.T, ip net.IP) *net.Resolver {
+	return answerResolver(t, map[uint16][][]byte{1: {ip.To4()}})
+}
+
+func TestSetApexALIASResolver(t *testing.T) {
+	zone := newFakeZone("example.com")
+	s, c := newTestServer(t, &Config{Resolver: addressResolver(t, net.IPv4(192, 0, 2, 10))}, zone.ServeHTTP)
+	if _, err := c.SetApexALIASContext(context.Background(), "example.com", "lb.example.net", 0); err != nil {
+		t.Fatal(err)
+	}
+	records := zone.Records()
+	if len(records) != 1 || records[0].Type != "ALIAS" || records[0].Content != "lb.example.net" {
+		t.Errorf("zone = %+v, want the apex ALIAS", records)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	sent := len(s.Requests())
+	if _, err := c.SetApexALIASContext(ctx, "example.com", "lb2.example.net", 0); err == nil {
+		t.Error("cancelled context: got no error")
+	}
+	if n := len(s.Requests()); n != sent {
+		t.Errorf("cancelled context sent %d requests", n-sent)
+	}
+}
+
+func TestSetApexALIASUnresolvable(t *testing.T) {
+	failing := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return nil, errors.New("no resolver here")
+		},
+	}
+	s, c := newTestServer(t, &Config{Resolver: failing}, newFakeZone("example.com").ServeHTTP)
+	if _, err := c.SetApexALIAS("example.com", "lb.example.net", 0); err == nil || !strings.Contains(err.Error(), "does not resolve") {
+		t.Errorf("got %v, want the target refused", err)
+	}
+	if n := len(s.Requests()); n != 0 {
+		t.Errorf("%d requests sent for an unresolvable target", n)
+	}
+}
@@ -0,0 +1,79 @@
+package porkbun
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+// ToASCII converts an internationalized domain or record name such as
+// "bücher.example" to the punycode form Porkbun expects,
+// "xn--bcher-kva.example". The client applies it to domains and record names
+// automatically.
+//
+// Conversion is golang.org/x/net/idna's Lookup profile, the UTS #46
+// processing registrars and resolvers apply: input is mapped and NFC
+// normalized, ideographic and fullwidth full stops separate labels, and the
+// bidi and contextual rules are checked. The profile is transitional, so
+// "straße" becomes "strasse". Existing "xn--" labels must decode to a valid
+// label. Plain ASCII labels are kept as is, so service labels such as
+// "_dmarc", which the Lookup profile would refuse, work.
+func ToASCII(name string) (string, error) {
+	if !utf8.ValidString(name) {
+		return "", fmt.Errorf("name %q is not valid UTF-8", name)
+	}
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if isASCII(label) && !hasACEPrefix(label) {
+			continue
+		}
+		ascii, err := idna.Lookup.ToASCII(label)
+		if err != nil {
+			return "", fmt.Errorf("label %q: %w", label, err)
+		}
+		labels[i] = ascii
+	}
+	return strings.Join(labels, "."), nil
+}
+
+// ToUnicode converts the punycode labels of name back to Unicode, e.g. to
+// display the names of retrieved records. Labels that do not decode to a
+// valid label, see ToASCII, are an error.
+func ToUnicode(name string) (string, error) {
+	labels := strings.Split(name, ".")
+	for i, label := range labels {
+		if !hasACEPrefix(label) {
+			continue
+		}
+		decoded, err := idna.Lookup.ToUnicode(label)
+		if err != nil {
+			return "", fmt.Errorf("label %q: %w", label, err)
+		}
+		labels[i] = decoded
+	}
+	return strings.Join(labels, "."), nil
+}
+
+func hasACEPrefix(label string) bool {
+	return len(label) >= 4 && strings.EqualFold(label[:4], "xn--")
+}
+
+// asciiName is ToASCII for internal use, leaving names it cannot convert
+// unchanged so the API reports them.
+func asciiName(name string) string {
+	if ascii, err := ToASCII(name); err == nil {
+		return ascii
+	}
+	return name
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
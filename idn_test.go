@@ -0,0 +1,67 @@
+package porkbun
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestToASCII(t *testing.T) {
+	for name, want := range map[string]string{
+		"bücher.example":        "xn--bcher-kva.example",
+		"BÜCHER.example":        "xn--bcher-kva.example",
+		"bu\u0308cher.example":  "xn--bcher-kva.example",
+		"bücher。example":        "xn--bcher-kva.example",
+		"münchen.de":            "xn--mnchen-3ya.de",
+		"例え.テスト":                "xn--r8jz45g.xn--zckzah",
+		"_dmarc.bücher.example": "_dmarc.xn--bcher-kva.example",
+		"www.example.com":       "www.example.com",
+		"xn--bcher-kva.example": "xn--bcher-kva.example",
+	} {
+		got, err := ToASCII(name)
+		if err != nil || got != want {
+			t.Errorf("ToASCII(%q) = %q, %v, want %q", name, got, err, want)
+			continue
+		}
+		back, err := ToUnicode(got)
+		if err != nil {
+			t.Errorf("ToUnicode(%q): %v", got, err)
+		}
+		if again, _ := ToASCII(back); again != want {
+			t.Errorf("ToASCII(ToUnicode(%q)) = %q", got, again)
+		}
+	}
+}
+
+func TestToASCIIInvalid(t *testing.T) {
+	for _, name := range []string{
+		"\u0301a.example", // leading combining mark
+		"-bücher.example",
+		"bü cher.example",
+		"xn--a.example",          // decodes to a control character
+		"xn--bcher-kvA9.example", // not the canonical encoding
+		"\xff.example",
+	} {
+		if got, err := ToASCII(name); err == nil {
+			t.Errorf("ToASCII(%q) = %q, want an error", name, got)
+		}
+	}
+	if _, err := ToUnicode("xn--a.example"); err == nil {
+		t.Error("ToUnicode accepted an invalid punycode label")
+	}
+}
+
+func TestUnicodeDomainRequestURL(t *testing.T) {
+	ts, c := newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS","records":[]}`))
+	if _, err := c.RetrieveRecords("bücher.example"); err != nil {
+		t.Fatal(err)
+	}
+	if got := ts.last(t).Path; got != "/dns/retrieve/xn--bcher-kva.example" {
+		t.Errorf("path %s, want the xn-- form", got)
+	}
+	if _, err := c.RetrieveRecordsByNameType("bücher.example", "A", "straße"); err != nil {
+		t.Fatal(err)
+	}
+	if got := ts.last(t).Path; got != "/dns/retrieveByNameType/xn--bcher-kva.example/A/strasse" {
+		t.Errorf("path %s, want the transitional mapping of ß", got)
+	}
+}
@@ -0,0 +1,382 @@
+package porkbun
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PORKBUN_MIN_TTL is the lowest TTL Porkbun applies; lower values are raised
+// to it by the API.
+const PORKBUN_MIN_TTL = 600
+
+// Kinds of issues reported by AnalyzeZone.
+const (
+	ZONE_ISSUE_APEX_CNAME     = "apex-cname"
+	ZONE_ISSUE_MULTIPLE_CNAME = "multiple-cname"
+	ZONE_ISSUE_MISSING_MX     = "missing-mx"
+	ZONE_ISSUE_LOW_TTL        = "low-ttl"
+	ZONE_ISSUE_DUPLICATE      = "duplicate"
+	ZONE_ISSUE_WHITESPACE     = "whitespace"
+)
+
+// ZoneIssue is a single finding of AnalyzeZone. Name is relative to the
+// domain, with "" for the apex.
+type ZoneIssue struct {
+	Kind    string
+	Name    string
+	Message string
+	Records []*DNSRecord
+}
+
+// ZoneReport holds the findings of AnalyzeZone, ordered by name.
+type ZoneReport struct {
+	Issues []ZoneIssue
+}
+
+// OK reports whether the analysis found nothing to flag.
+func (r ZoneReport) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// AnalyzeZone lints a record set of domain: a CNAME at the apex, several
+// CNAMEs at one name, an SPF policy without MX records, TTLs below
+// PORKBUN_MIN_TTL, duplicate records and content with leading or trailing
+// whitespace, which breaks verification tokens while looking right. Names
+// may be relative or fully qualified.
+func AnalyzeZone(records []*DNSRecord, domain string) ZoneReport {
+	var report ZoneReport
+	byName := groupByName(records, domain)
+	for _, name := range sortedKeys(byName) {
+		group := byName[name]
+		var cnames, mx, spf []*DNSRecord
+		for _, record := range group {
+			switch strings.ToUpper(record.Type) {
+			case "CNAME":
+				cnames = append(cnames, record)
+			case "MX":
+				mx = append(mx, record)
+			case "TXT":
+				if isMailSPF(record.Content) {
+					spf = append(spf, record)
+				}
+			}
+			if ttl, err := strconv.Atoi(record.TTL); err == nil && ttl > 0 && ttl < PORKBUN_MIN_TTL {
+				report.add(ZONE_ISSUE_LOW_TTL, name, fmt.Sprintf("%s record has TTL %d, below the minimum of %d", record.Type, ttl, PORKBUN_MIN_TTL), record)
+			}
+			if record.Content != strings.TrimSpace(record.Content) {
+				report.add(ZONE_ISSUE_WHITESPACE, name, fmt.Sprintf("%s record content %q has leading or trailing whitespace", record.Type, record.Content), record)
+			}
+		}
+		if name == "" && len(cnames) > 0 {
+			report.add(ZONE_ISSUE_APEX_CNAME, name, "CNAME records are not allowed at the zone apex, use ALIAS instead", cnames...)
+		}
+		if len(cnames) > 1 {
+			report.add(ZONE_ISSUE_MULTIPLE_CNAME, name, fmt.Sprintf("%d CNAME records at the same name", len(cnames)), cnames...)
+		}
+		if len(spf) > 0 && len(mx) == 0 {
+			report.add(ZONE_ISSUE_MISSING_MX, name, "SPF policy allows mail but there is no MX record", spf...)
+		}
+		for _, dups := range duplicateGroups(group) {
+			report.add(ZONE_ISSUE_DUPLICATE, name, fmt.Sprintf("%d identical %s records", len(dups), dups[0].Type), dups...)
+		}
+	}
+	return report
+}
+
+// ValidateRecords checks a desired record set of domain before it is
+// applied, returning every problem found rather than only the first: each
+// record must pass Validate, and a CNAME must be the only record at its
+// name and may not be at the apex. It returns nil when the set is valid.
+func ValidateRecords(domain string, records []*DNSRecord) []error {
+	var errs []error
+	for i, record := range records {
+		if err := record.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("record %d (%s %q): %w", i, record.Type, record.Name, err))
+		}
+	}
+	byName := groupByName(records, domain)
+	for _, name := range sortedKeys(byName) {
+		group := byName[name]
+		cnames := 0
+		for _, record := range group {
+			if strings.EqualFold(record.Type, "CNAME") {
+				cnames++
+			}
+		}
+		switch {
+		case cnames == 0:
+			continue
+		case name == "":
+			errs = append(errs, fmt.Errorf("CNAME records are not allowed at the zone apex, use ALIAS instead"))
+		case cnames > 1:
+			errs = append(errs, fmt.Errorf("%d CNAME records at %q, only one is allowed", cnames, name))
+		}
+		if others := len(group) - cnames; others > 0 {
+			errs = append(errs, fmt.Errorf("CNAME at %q coexists with %d other records", name, others))
+		}
+	}
+	return errs
+}
+
+// FindTTLViolations returns the records whose TTL is not one of allowed,
+// to report exceptions to a TTL policy; SetZoneTTL can then correct them.
+// An empty or "0" TTL, which Porkbun replaces by its default, counts as
+// PORKBUN_MIN_TTL, and a TTL that is not a number is always a violation.
+func FindTTLViolations(records []*DNSRecord, allowed []int) []*DNSRecord {
+	ok := make(map[int]bool, len(allowed))
+	for _, ttl := range allowed {
+		ok[ttl] = true
+	}
+	var violations []*DNSRecord
+	for _, record := range records {
+		ttl := PORKBUN_MIN_TTL
+		if record.TTL != "" && record.TTL != "0" {
+			n, err := strconv.Atoi(record.TTL)
+			if err != nil {
+				violations = append(violations, record)
+				continue
+			}
+			ttl = n
+		}
+		if !ok[ttl] {
+			violations = append(violations, record)
+		}
+	}
+	return violations
+}
+
+// ZoneStats summarizes the composition of a record set, see SummarizeZone.
+type ZoneStats struct {
+	Total  int
+	ByType map[string]int
+	// MinTTL, MaxTTL and AvgTTL are in seconds over the records with a
+	// valid TTL, an empty or "0" one counting as PORKBUN_MIN_TTL; they are
+	// zero when there are none.
+	MinTTL int
+	MaxTTL int
+	AvgTTL float64
+	// Wildcards counts records named "*" or "*.something".
+	Wildcards int
+}
+
+// SummarizeZone counts the records by type and reports their TTL range and
+// the number of wildcards, for dashboards and audit reports.
+func SummarizeZone(records []*DNSRecord) ZoneStats {
+	stats := ZoneStats{Total: len(records), ByType: make(map[string]int)}
+	var sum, counted int
+	for _, record := range records {
+		stats.ByType[strings.ToUpper(record.Type)]++
+		if name := record.Name; name == "*" || strings.HasPrefix(name, "*.") {
+			stats.Wildcards++
+		}
+		ttl := PORKBUN_MIN_TTL
+		if record.TTL != "" && record.TTL != "0" {
+			n, err := strconv.Atoi(record.TTL)
+			if err != nil {
+				continue
+			}
+			ttl = n
+		}
+		if counted == 0 || ttl < stats.MinTTL {
+			stats.MinTTL = ttl
+		}
+		if ttl > stats.MaxTTL {
+			stats.MaxTTL = ttl
+		}
+		sum += ttl
+		counted++
+	}
+	if counted > 0 {
+		stats.AvgTTL = float64(sum) / float64(counted)
+	}
+	return stats
+}
+
+// FindParkingRecords returns the A and AAAA records pointing at one of
+// parkingIPs, e.g. placeholder or parking addresses of a host that was
+// migrated away from. Addresses compare by value, so "::1" and
+// "0:0:0:0:0:0:0:1" match.
+func FindParkingRecords(records []*DNSRecord, parkingIPs []string) []*DNSRecord {
+	var parking []net.IP
+	for _, addr := range parkingIPs {
+		if ip := net.ParseIP(strings.TrimSpace(addr)); ip != nil {
+			parking = append(parking, ip)
+		}
+	}
+	var found []*DNSRecord
+	for _, record := range records {
+		switch strings.ToUpper(record.Type) {
+		case "A", "AAAA":
+		default:
+			continue
+		}
+		ip := net.ParseIP(strings.TrimSpace(record.Content))
+		for _, p := range parking {
+			if ip != nil && ip.Equal(p) {
+				found = append(found, record)
+				break
+			}
+		}
+	}
+	return found
+}
+
+// ShadowWarning reports records made ineffective by another record at the
+// same name, found by AnalyzeShadowing. Name is relative to the domain.
+type ShadowWarning struct {
+	Name     string
+	Record   *DNSRecord
+	Shadowed []*DNSRecord
+	Message  string
+}
+
+// AnalyzeShadowing flags names of domain where a record hides others: a
+// CNAME next to records of any other type, such as A or MX, which DNS does
+// not allow, and an ALIAS next to A or AAAA records answering the same
+// queries. Porkbun accepts both silently.
+func AnalyzeShadowing(records []*DNSRecord, domain string) []ShadowWarning {
+	var warnings []ShadowWarning
+	byName := groupByName(records, domain)
+	for _, name := range sortedKeys(byName) {
+		for _, record := range byName[name] {
+			var shadowed []*DNSRecord
+			typ := strings.ToUpper(record.Type)
+			for _, other := range byName[name] {
+				otherType := strings.ToUpper(other.Type)
+				switch {
+				case typ == "CNAME" && otherType != "CNAME":
+					shadowed = append(shadowed, other)
+				case typ == "ALIAS" && (otherType == "A" || otherType == "AAAA"):
+					shadowed = append(shadowed, other)
+				}
+			}
+			if len(shadowed) == 0 {
+				continue
+			}
+			warnings = append(warnings, ShadowWarning{
+				Name:     name,
+				Record:   record,
+				Shadowed: shadowed,
+				Message:  fmt.Sprintf("%s record shadows %d other records at the same name", typ, len(shadowed)),
+			})
+		}
+	}
+	return warnings
+}
+
+// AnalyzeDanglingCNAMEs returns the CNAME records of domain whose target
+// lies within the zone but has no record in the set, e.g. after the target
+// was deleted. A wildcard record covering the target counts as existing;
+// targets outside the zone are not checked.
+func AnalyzeDanglingCNAMEs(records []*DNSRecord, domain string) []*DNSRecord {
+	byName := groupByName(records, domain)
+	var dangling []*DNSRecord
+	for _, record := range records {
+		if !strings.EqualFold(record.Type, "CNAME") {
+			continue
+		}
+		target, ok := zoneName(record.Content, domain)
+		if ok && !nameExists(byName, target) {
+			dangling = append(dangling, record)
+		}
+	}
+	return dangling
+}
+
+// zoneName returns the name key of a fully qualified hostname within
+// domain, reporting whether it lies within the zone at all.
+func zoneName(host string, domain string) (string, bool) {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	if host != domain && !strings.HasSuffix(host, "."+domain) {
+		return "", false
+	}
+	return nameKey(host, domain), true
+}
+
+// nameExists reports whether name has records in byName, directly or
+// through the closest wildcard.
+func nameExists(byName map[string][]*DNSRecord, name string) bool {
+	if len(byName[name]) > 0 {
+		return true
+	}
+	for parent := name; parent != ""; {
+		i := strings.IndexByte(parent, '.')
+		if i < 0 {
+			parent = ""
+		} else {
+			parent = parent[i+1:]
+		}
+		wildcard := "*"
+		if parent != "" {
+			wildcard += "." + parent
+		}
+		if len(byName[wildcard]) > 0 {
+			return true
+		}
+		if len(byName[parent]) > 0 && parent != "" {
+			// An existing name stops wildcard matching below it.
+			return false
+		}
+	}
+	return false
+}
+
+func (r *ZoneReport) add(kind string, name string, message string, records ...*DNSRecord) {
+	r.Issues = append(r.Issues, ZoneIssue{Kind: kind, Name: name, Message: message, Records: records})
+}
+
+// isMailSPF reports whether content is an SPF policy that permits sending,
+// as opposed to the "v=spf1 -all" policy of domains without mail.
+func isMailSPF(content string) bool {
+	fields := strings.Fields(strings.ToLower(strings.Trim(content, `"`)))
+	if len(fields) == 0 || fields[0] != "v=spf1" {
+		return false
+	}
+	return !(len(fields) == 2 && fields[1] == "-all")
+}
+
+// groupByName groups records by their lowercased name relative to domain.
+func groupByName(records []*DNSRecord, domain string) map[string][]*DNSRecord {
+	byName := make(map[string][]*DNSRecord)
+	for _, record := range records {
+		name := nameKey(record.Name, domain)
+		byName[name] = append(byName[name], record)
+	}
+	return byName
+}
+
+// duplicateGroups returns the groups of records sharing type and content,
+// keeping only groups with more than one member. All records are expected
+// to have the same name.
+func duplicateGroups(records []*DNSRecord) [][]*DNSRecord {
+	seen := make(map[string][]*DNSRecord)
+	var order []string
+	for _, record := range records {
+		r := normalizeRecord(*record)
+		key := strings.ToUpper(r.Type) + " " + r.Content
+		if _, ok := seen[key]; !ok {
+			order = append(order, key)
+		}
+		seen[key] = append(seen[key], record)
+	}
+	var groups [][]*DNSRecord
+	for _, key := range order {
+		if len(seen[key]) > 1 {
+			groups = append(groups, seen[key])
+		}
+	}
+	return groups
+}
+
+func sortedKeys(m map[string][]*DNSRecord) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
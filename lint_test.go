@@ -0,0 +1,29 @@
+package porkbun
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestTrailingWhitespace(t *testing.T) {
+	s, c := newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS","id":"1"}`))
+	if _, err := c.CreateRecord("example.com", &DNSRecord{Name: "_verify", Type: "TXT", Content: "token-abc123 "}); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := s.last(t).field(t, "content"); content != "token-abc123" {
+		t.Errorf("content = %q, want the trailing space trimmed", content)
+	}
+
+	records := []*DNSRecord{
+		{ID: "1", Name: "_verify.example.com", Type: "TXT", Content: "token-abc123 ", TTL: "600"},
+		{ID: "2", Name: "_other.example.com", Type: "TXT", Content: "token-abc123", TTL: "600"},
+	}
+	report := AnalyzeZone(records, "example.com")
+	if len(report.Issues) != 1 {
+		t.Fatalf("issues = %+v, want one", report.Issues)
+	}
+	issue := report.Issues[0]
+	if issue.Kind != ZONE_ISSUE_WHITESPACE || issue.Name != "_verify" || len(issue.Records) != 1 || issue.Records[0].ID != "1" {
+		t.Errorf("issue = %+v, want whitespace on record 1", issue)
+	}
+}
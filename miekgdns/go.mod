@@ -0,0 +1,18 @@
+module github.com/blmhemu/porkbun-go/miekgdns
+
+go 1.25.0
+
+require (
+	github.com/blmhemu/porkbun-go v0.0.0
+	github.com/miekg/dns v1.1.73
+)
+
+require (
+	golang.org/x/net v0.57.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+	golang.org/x/text v0.40.0 // indirect
+)
+
+// The converters are developed against the porkbun package in the parent
+// directory.
+replace github.com/blmhemu/porkbun-go => ../
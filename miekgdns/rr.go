@@ -0,0 +1,150 @@
+// Package miekgdns converts between porkbun.DNSRecord and the resource
+// records of github.com/miekg/dns. It is a module of its own so that the
+// porkbun package does not depend on miekg/dns.
+package miekgdns
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	porkbun "github.com/blmhemu/porkbun-go"
+	"github.com/miekg/dns"
+)
+
+// supportedTypes are the record types Porkbun accepts that have a DNS wire
+// type.
+var supportedTypes = map[string]bool{
+	"A":     true,
+	"AAAA":  true,
+	"CAA":   true,
+	"CNAME": true,
+	"HTTPS": true,
+	"MX":    true,
+	"NS":    true,
+	"SRV":   true,
+	"SVCB":  true,
+	"TLSA":  true,
+	"TXT":   true,
+}
+
+// ToRR converts record to a dns.RR within domain. ALIAS records have no DNS
+// wire type and cannot be converted. An empty or "0" TTL becomes
+// porkbun.PORKBUN_MIN_TTL, the default Porkbun applies.
+func ToRR(record *porkbun.DNSRecord, domain string) (dns.RR, error) {
+	typ := strings.ToUpper(record.Type)
+	content := strings.TrimSpace(record.Content)
+	var rdata string
+	switch typ {
+	case "ALIAS":
+		return nil, fmt.Errorf("ALIAS records have no DNS equivalent")
+	case "CNAME", "NS":
+		rdata = dns.Fqdn(content)
+	case "MX":
+		rdata = prioOrZero(record.Prio) + " " + dns.Fqdn(content)
+	case "SRV":
+		fields := strings.Fields(content)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("SRV record content must be \"weight port target\"")
+		}
+		rdata = prioOrZero(record.Prio) + " " + fields[0] + " " + fields[1] + " " + dns.Fqdn(fields[2])
+	case "TXT":
+		rdata = content
+		if len(content) < 2 || content[0] != '"' || content[len(content)-1] != '"' {
+			rdata = quoteTXT(content)
+		}
+	default:
+		rdata = content
+	}
+	ttl := record.TTL
+	if ttl == "" || ttl == "0" {
+		ttl = strconv.Itoa(porkbun.PORKBUN_MIN_TTL)
+	}
+	return dns.NewRR(fmt.Sprintf("%s %s IN %s %s", dns.Fqdn(record.FQDN(domain)), ttl, typ, rdata))
+}
+
+// FromRR converts a dns.RR to a record with a fully qualified name, the form
+// the retrieve endpoint returns, so it can be created or compared directly.
+func FromRR(rr dns.RR) (*porkbun.DNSRecord, error) {
+	h := rr.Header()
+	typ, ok := dns.TypeToString[h.Rrtype]
+	if !ok || !supportedTypes[typ] {
+		return nil, fmt.Errorf("record type %d is not supported by Porkbun", h.Rrtype)
+	}
+	record := &porkbun.DNSRecord{
+		Name: strings.TrimSuffix(h.Name, "."),
+		Type: typ,
+		TTL:  strconv.FormatUint(uint64(h.Ttl), 10),
+	}
+	switch v := rr.(type) {
+	case *dns.CNAME:
+		record.Content = strings.TrimSuffix(v.Target, ".")
+	case *dns.NS:
+		record.Content = strings.TrimSuffix(v.Ns, ".")
+	case *dns.MX:
+		record.Prio = strconv.Itoa(int(v.Preference))
+		record.Content = strings.TrimSuffix(v.Mx, ".")
+	case *dns.SRV:
+		record.Prio = strconv.Itoa(int(v.Priority))
+		record.Content = fmt.Sprintf("%d %d %s", v.Weight, v.Port, strings.TrimSuffix(v.Target, "."))
+	case *dns.TXT:
+		parts := make([]string, len(v.Txt))
+		for i, part := range v.Txt {
+			parts[i] = unescapeTXT(part)
+		}
+		record.Content = strings.Join(parts, "")
+	default:
+		record.Content = strings.TrimPrefix(rr.String(), h.String())
+	}
+	return record, nil
+}
+
+// prioOrZero returns prio, or "0" when it is unset.
+func prioOrZero(prio string) string {
+	if prio == "" {
+		return "0"
+	}
+	return prio
+}
+
+// quoteTXT splits content into quoted strings of at most 255 bytes, the
+// limit of a single TXT character string.
+func quoteTXT(content string) string {
+	quote := func(s string) string {
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+	}
+	var parts []string
+	for len(content) > 255 {
+		parts = append(parts, quote(content[:255]))
+		content = content[255:]
+	}
+	parts = append(parts, quote(content))
+	return strings.Join(parts, " ")
+}
+
+// unescapeTXT undoes the presentation escapes miekg/dns keeps in TXT
+// strings, \X for X and \DDD for the byte with that decimal value.
+func unescapeTXT(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+3 < len(s) && isDigit(s[i+1]) && isDigit(s[i+2]) && isDigit(s[i+3]) {
+			if n, err := strconv.Atoi(s[i+1 : i+4]); err == nil && n < 256 {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		i++
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
@@ -0,0 +1,48 @@
+package miekgdns
+
+import (
+	"strings"
+	"testing"
+
+	porkbun "github.com/blmhemu/porkbun-go"
+)
+
+func TestRoundTrip(t *testing.T) {
+	long := strings.Repeat("k", 300)
+	for _, record := range []porkbun.DNSRecord{
+		{Name: "www.example.com", Type: "A", Content: "1.1.1.1", TTL: "600"},
+		{Name: "example.com", Type: "AAAA", Content: "2001:db8::1", TTL: "3600"},
+		{Name: "blog.example.com", Type: "CNAME", Content: "example.net", TTL: "600"},
+		{Name: "example.com", Type: "MX", Content: "mx.example.com", TTL: "600", Prio: "10"},
+		{Name: "_sip._tcp.example.com", Type: "SRV", Content: "5 5060 sip.example.com", TTL: "600", Prio: "0"},
+		{Name: "example.com", Type: "TXT", Content: `v=spf1 include:_spf.porkbun.com "quoted" \\ -all`, TTL: "600"},
+		{Name: "dkim._domainkey.example.com", Type: "TXT", Content: long, TTL: "600"},
+		{Name: "example.com", Type: "CAA", Content: `0 issue "letsencrypt.org"`, TTL: "600"},
+		{Name: "example.com", Type: "NS", Content: "curitiba.ns.porkbun.com", TTL: "86400"},
+	} {
+		rr, err := ToRR(&record, "example.com")
+		if err != nil {
+			t.Fatalf("%+v: %v", record, err)
+		}
+		back, err := FromRR(rr)
+		if err != nil {
+			t.Fatalf("%s: %v", rr, err)
+		}
+		if *back != record {
+			t.Errorf("round trip through %s\ngot  %+v\nwant %+v", rr, *back, record)
+		}
+	}
+}
+
+func TestToRRDefaults(t *testing.T) {
+	rr, err := ToRR(&porkbun.DNSRecord{Name: "www", Type: "txt", Content: `"already quoted"`, TTL: "0"}, "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rr.String(), "www.example.com.\t600\tIN\tTXT\t\"already quoted\""; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if _, err := ToRR(&porkbun.DNSRecord{Name: "", Type: "ALIAS", Content: "lb.example.net"}, "example.com"); err == nil {
+		t.Error("ALIAS converted")
+	}
+}
@@ -0,0 +1,120 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DEFAULT_PACE_INTERVAL is the gap ApplyRecordsPaced leaves between
+// operations when PaceOptions.Interval is zero.
+const DEFAULT_PACE_INTERVAL = 500 * time.Millisecond
+
+// PaceOptions controls how ApplyRecordsPaced spaces and retries operations.
+type PaceOptions struct {
+	// Interval is the minimum time between the start of two operations;
+	// zero means DEFAULT_PACE_INTERVAL.
+	Interval time.Duration
+	// Retries is how many more times an operation is attempted after a
+	// transient failure, on top of the retries of Config.MaxRetries within
+	// each attempt. Creates and edits are only retried when they were
+	// turned away by a rate limit or a maintenance page, or with
+	// Config.RetryNonIdempotent, so they cannot be applied twice.
+	Retries int
+	// StopOnError aborts at the first operation that still fails after its
+	// retries, returning the results so far, which end with the failure.
+	StopOnError bool
+	// Progress, when set, is called after every operation with the number
+	// done so far and the total.
+	Progress func(done int, total int)
+}
+
+// PaceResult holds the outcome of every operation ApplyRecordsPaced ran, in
+// order, and how many retries it took.
+type PaceResult struct {
+	PlanResult
+	Retries int
+}
+
+// ApplyRecordsPaced runs ops against domain one at a time, at most one per
+// PaceOptions.Interval, for large batches that would otherwise trip
+// Porkbun's rate limits. While the last response reports an exhausted quota,
+// it waits for the window to reset, and after a rate-limit rejection for as
+// long as the server asked. Operations with an empty Domain use domain;
+// ones naming another domain fail. When ctx is cancelled the operation in
+// flight is still recorded and reported to Progress, the operations not yet
+// run are left out of the result, and the context's error is returned.
+func (c *Client) ApplyRecordsPaced(ctx context.Context, domain string, ops []Operation, opts PaceOptions) (*PaceResult, error) {
+	if err := requireDomain(domain); err != nil {
+		return nil, err
+	}
+	interval := opts.Interval
+	if interval <= 0 {
+		interval = DEFAULT_PACE_INTERVAL
+	}
+	clock := c.clock()
+	result := &PaceResult{}
+	var next time.Time
+	for i, op := range ops {
+		if op.Domain == "" {
+			op.Domain = domain
+		}
+		var id string
+		var err, lastErr error
+		if op.Domain != domain {
+			err = fmt.Errorf("operation is for %s, not %s", op.Domain, domain)
+		}
+		for attempt := 0; err == nil; attempt++ {
+			if waitErr := c.paceWait(ctx, next); waitErr != nil {
+				if attempt == 0 {
+					return result, waitErr
+				}
+				err = lastErr
+				break
+			}
+			next = clock.Now().Add(interval)
+			id, err = c.runOperation(ctx, op)
+			if err == nil || ctx.Err() != nil || attempt >= opts.Retries || !c.retryableOperation(op, err) {
+				break
+			}
+			result.Retries++
+			if after := c.LastQuota().RetryAfter; errors.Is(err, ErrRateLimited) && clock.Now().Add(after).After(next) {
+				next = clock.Now().Add(after)
+			}
+			lastErr, err = err, nil
+		}
+		result.Results = append(result.Results, BulkResult{ID: id, Record: op.Record, Err: err})
+		if opts.Progress != nil {
+			opts.Progress(i+1, len(ops))
+		}
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+		if err != nil && opts.StopOnError {
+			return result, fmt.Errorf("operation %d (%s %s in %s): %w", i, op.Op, op.target(), op.Domain, err)
+		}
+	}
+	return result, nil
+}
+
+// paceWait sleeps until next, or until the rate-limit window resets while
+// the last response reported no requests remaining.
+func (c *Client) paceWait(ctx context.Context, next time.Time) error {
+	if quota := c.LastQuota(); quota.Known && quota.Remaining == 0 && quota.Reset.After(next) {
+		next = quota.Reset
+	}
+	clock := c.clock()
+	return sleep(ctx, clock, next.Sub(clock.Now()))
+}
+
+// retryableOperation reports whether op may be attempted again after err:
+// rate limits and maintenance pages always, other failures to get a
+// response only when mayRepeat allows it for the operation, as for deletes.
+func (c *Client) retryableOperation(op Operation, err error) bool {
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, ErrServiceMaintenance) {
+		return true
+	}
+	var send *sendError
+	return errors.As(err, &send) && c.mayRepeat(op.Op == AUDIT_OP_DELETE, nil, err)
+}
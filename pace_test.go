@@ -0,0 +1,85 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplyRecordsPacedCancelRecordsInFlight(t *testing.T) {
+	zone := newFakeZone("example.com")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	creates := 0
+	s, c := newTestServer(t, nil, func(w http.ResponseWriter, r *http.Request) {
+		creates++
+		if creates == 2 {
+			cancel()
+		}
+		zone.ServeHTTP(w, r)
+	})
+	var ops []Operation
+	for _, content := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3"} {
+		ops = append(ops, Operation{Op: AUDIT_OP_CREATE, Record: &DNSRecord{Name: "www", Type: "A", Content: content}})
+	}
+	var progress []int
+	result, err := c.ApplyRecordsPaced(ctx, "example.com", ops, PaceOptions{
+		Interval: time.Millisecond,
+		Progress: func(done int, total int) { progress = append(progress, done) },
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if len(result.Results) != 2 || result.Results[0].Err != nil || result.Results[1].Record != ops[1].Record {
+		t.Errorf("results = %+v, want the first two operations", result.Results)
+	}
+	if len(progress) != 2 || progress[1] != 2 {
+		t.Errorf("progress = %v, want it called for the operation in flight", progress)
+	}
+	if n := len(s.Requests()); n != 2 {
+		t.Errorf("%d creates sent, want the third left out", n)
+	}
+}
+
+func TestApplyRecordsPacedRetriesOnlyIdempotent(t *testing.T) {
+	for _, tt := range []struct {
+		retryNonIdempotent bool
+		edits, deletes     int
+	}{
+		{false, 1, 3},
+		{true, 3, 3},
+	} {
+		calls := map[string]int{}
+		c, err := NewClient(&Config{
+			Auth:               Auth{APIKey: "pk1_test", SecretAPIKey: "sk1_test"},
+			RetryNonIdempotent: tt.retryNonIdempotent,
+			Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				calls[strings.Split(strings.TrimPrefix(r.URL.Path, "/api/json/v3/dns/"), "/")[0]]++
+				return nil, errors.New("connection reset by peer")
+			}),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ops := []Operation{
+			{Op: AUDIT_OP_EDIT, ID: "1", Record: &DNSRecord{Name: "www", Type: "A", Content: "192.0.2.1"}},
+			{Op: AUDIT_OP_DELETE, ID: "2"},
+		}
+		result, err := c.ApplyRecordsPaced(context.Background(), "example.com", ops, PaceOptions{Interval: time.Millisecond, Retries: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range result.Results {
+			if r.Err == nil {
+				t.Errorf("%s: got no error", r.ID)
+			}
+		}
+		if calls["edit"] != tt.edits || calls["delete"] != tt.deletes {
+			t.Errorf("RetryNonIdempotent %t: sent %d edits and %d deletes, want %d and %d",
+				tt.retryNonIdempotent, calls["edit"], calls["delete"], tt.edits, tt.deletes)
+		}
+	}
+}
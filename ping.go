@@ -0,0 +1,54 @@
+package porkbun
+
+import (
+	"context"
+	"fmt"
+)
+
+const PORKBUN_PING = PORKBUN_API_BASE + "/ping"
+
+type PingResponse struct {
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+	YourIP  string `json:"yourIp,omitempty"`
+}
+
+func (r *PingResponse) status() (string, string) { return r.Status, r.Message }
+
+func (r *PingResponse) validate() error {
+	if r.YourIP == "" {
+		return fmt.Errorf("ping returned no IP address")
+	}
+	return nil
+}
+
+// Ping checks the credentials and returns the public IP address the request
+// came from, as seen by Porkbun, which dynamic DNS clients can use.
+func (c *Client) Ping() (string, error) {
+	return c.ping(context.Background())
+}
+
+func (c *Client) ping(ctx context.Context) (string, error) {
+	authjson, err := c.getAuthJson()
+	if err != nil {
+		return "", err
+	}
+	var d PingResponse
+	if err := c.post(ctx, PORKBUN_PING, authjson, &d); err != nil {
+		return "", err
+	}
+	return d.YourIP, nil
+}
+
+// HealthCheck pings Porkbun to validate the credentials and retrieves the
+// zone of domain to confirm API access to it, returning the first failure.
+// It suits a readiness probe.
+func (c *Client) HealthCheck(ctx context.Context, domain string) error {
+	if _, err := c.ping(ctx); err != nil {
+		return fmt.Errorf("ping: %w", err)
+	}
+	if _, err := c.retrieveRecords(ctx, domain); err != nil {
+		return fmt.Errorf("retrieving %s: %w", domain, err)
+	}
+	return nil
+}
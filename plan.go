@@ -0,0 +1,144 @@
+package porkbun
+
+import (
+	"context"
+	"fmt"
+)
+
+// Operation is a single queued change. Op is one of AUDIT_OP_CREATE,
+// AUDIT_OP_EDIT and AUDIT_OP_DELETE; ID is unused for creates and Record for
+// deletes.
+type Operation struct {
+	Op     string
+	Domain string
+	ID     string
+	Record *DNSRecord
+}
+
+// Plan is an ordered batch of operations run by Execute. Operations can be
+// inspected and logged before anything is sent. With StopOnError, Execute
+// stops at the first failing operation.
+type Plan struct {
+	Operations  []Operation
+	StopOnError bool
+}
+
+// Create queues the creation of record in domain.
+func (p *Plan) Create(domain string, record *DNSRecord) *Plan {
+	p.Operations = append(p.Operations, Operation{Op: AUDIT_OP_CREATE, Domain: domain, Record: record})
+	return p
+}
+
+// Edit queues an edit of the record with the given id.
+func (p *Plan) Edit(domain string, id string, record *DNSRecord) *Plan {
+	p.Operations = append(p.Operations, Operation{Op: AUDIT_OP_EDIT, Domain: domain, ID: id, Record: record})
+	return p
+}
+
+// Delete queues the deletion of the record with the given id.
+func (p *Plan) Delete(domain string, id string) *Plan {
+	p.Operations = append(p.Operations, Operation{Op: AUDIT_OP_DELETE, Domain: domain, ID: id})
+	return p
+}
+
+// PlanResult holds the outcome of every operation Execute ran, in plan
+// order. The ID of a create is the new record's.
+type PlanResult struct {
+	Results []BulkResult
+}
+
+// Failed returns the results of the operations that failed.
+func (r *PlanResult) Failed() []BulkResult {
+	var failed []BulkResult
+	for _, result := range r.Results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Execute runs the operations of p in order. Without StopOnError every
+// operation runs and failures are only reported in the results, with those
+// not yet run when ctx is cancelled failing with the context's error. With
+// StopOnError, Execute returns after the first failure with an error naming
+// the operation, and the results end with it.
+func (c *Client) Execute(ctx context.Context, p *Plan) (*PlanResult, error) {
+	result := &PlanResult{}
+	for i, op := range p.Operations {
+		var id string
+		err := ctxErrOr(ctx, func() (err error) {
+			id, err = c.runOperation(ctx, op)
+			return err
+		})
+		result.Results = append(result.Results, BulkResult{ID: id, Record: op.Record, Err: err})
+		if err != nil && p.StopOnError {
+			return result, fmt.Errorf("operation %d (%s %s in %s): %w", i, op.Op, op.target(), op.Domain, err)
+		}
+	}
+	return result, nil
+}
+
+// runOperation performs op, returning the ID of the record it touched.
+func (c *Client) runOperation(ctx context.Context, op Operation) (string, error) {
+	switch op.Op {
+	case AUDIT_OP_CREATE:
+		if op.Record == nil {
+			return "", fmt.Errorf("create needs a record")
+		}
+		return c.createRecord(ctx, op.Domain, op.Record)
+	case AUDIT_OP_EDIT:
+		if op.Record == nil {
+			return op.ID, fmt.Errorf("edit needs a record")
+		}
+		return op.ID, c.editRecord(ctx, op.Domain, op.ID, op.Record)
+	case AUDIT_OP_DELETE:
+		return op.ID, c.deleteRecord(ctx, op.Domain, op.ID)
+	}
+	return op.ID, fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// target describes what op acts on, for error messages.
+func (op Operation) target() string {
+	if op.ID != "" {
+		return "record " + op.ID
+	}
+	if op.Record != nil {
+		return op.Record.Type + " record " + op.Record.Name
+	}
+	return "record"
+}
+
+// PreviewRequest builds the request op would send without sending it, for
+// debugging integrations. The API key and secret in the returned body are
+// replaced with REDACTED.
+func (c *Client) PreviewRequest(op Operation) (method string, url string, body []byte, err error) {
+	switch op.Op {
+	case AUDIT_OP_CREATE, AUDIT_OP_EDIT:
+		if op.Record == nil {
+			return "", "", nil, fmt.Errorf("%s needs a record", op.Op)
+		}
+		if op.Op == AUDIT_OP_CREATE {
+			err = requireDomain(op.Domain)
+			url = endpoint(PORKBUN_DNS_CREATE, op.Domain)
+		} else {
+			err = requireDomainAndID(op.Domain, op.ID)
+			url = endpoint(PORKBUN_DNS_EDIT, op.Domain, op.ID)
+		}
+		if err == nil {
+			body, err = c.getDNSRecordWithAuthJson(op.Domain, op.Record)
+		}
+	case AUDIT_OP_DELETE:
+		err = requireDomainAndID(op.Domain, op.ID)
+		url = endpoint(PORKBUN_DNS_DELETE, op.Domain, op.ID)
+		if err == nil {
+			body, err = c.getAuthJson()
+		}
+	default:
+		err = fmt.Errorf("unknown operation %q", op.Op)
+	}
+	if err != nil {
+		return "", "", nil, err
+	}
+	return PORKBUN_HTTP_METHOD, c.requestURL(url), []byte(c.maskSecrets(string(body))), nil
+}
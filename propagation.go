@@ -0,0 +1,195 @@
+package porkbun
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// CheckPropagation queries each resolver, an address such as "1.1.1.1" or
+// "8.8.8.8:53", for the recordType records of fqdn and reports per resolver
+// whether one of the answers equals expected. A resolver that fails or times
+// out counts as not propagated. Supported types are A, AAAA, CNAME, MX, NS
+// and TXT; the error is only set for other types.
+func CheckPropagation(ctx context.Context, fqdn string, recordType string, resolvers []string, expected string) (map[string]bool, error) {
+	typ := strings.ToUpper(recordType)
+	switch typ {
+	case "A", "AAAA", "CNAME", "MX", "NS", "TXT":
+	default:
+		return nil, fmt.Errorf("propagation checks do not support %s records", recordType)
+	}
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]bool, len(resolvers))
+	)
+	for _, resolver := range resolvers {
+		wg.Add(1)
+		go func(resolver string) {
+			defer wg.Done()
+			answers, err := lookup(ctx, newResolver(resolver), asciiName(fqdn), typ)
+			ok := err == nil && containsAnswer(typ, answers, expected)
+			mu.Lock()
+			results[resolver] = ok
+			mu.Unlock()
+		}(resolver)
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// newResolver returns a resolver sending every query to addr, port 53 unless
+// addr names one.
+func newResolver(addr string) *net.Resolver {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// lookup returns the answers for name in presentation form.
+func lookup(ctx context.Context, r *net.Resolver, name string, typ string) ([]string, error) {
+	var answers []string
+	switch typ {
+	case "A", "AAAA":
+		network := "ip4"
+		if typ == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := r.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			answers = append(answers, ip.String())
+		}
+	case "CNAME":
+		cname, err := r.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, cname)
+	case "MX":
+		mxs, err := r.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			answers = append(answers, mx.Host)
+		}
+	case "NS":
+		nss, err := r.LookupNS(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nss {
+			answers = append(answers, ns.Host)
+		}
+	case "TXT":
+		return r.LookupTXT(ctx, name)
+	}
+	return answers, nil
+}
+
+// containsAnswer compares answers with expected the way the type demands:
+// addresses by value, hostnames case-insensitively without trailing dots
+// and TXT strings exactly.
+func containsAnswer(typ string, answers []string, expected string) bool {
+	for _, answer := range answers {
+		switch typ {
+		case "A", "AAAA":
+			if ip := net.ParseIP(expected); ip != nil && ip.Equal(net.ParseIP(answer)) {
+				return true
+			}
+		case "TXT":
+			if answer == expected {
+				return true
+			}
+		default:
+			if strings.EqualFold(strings.TrimSuffix(answer, "."), strings.TrimSuffix(expected, ".")) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RecordFromLookup resolves the recordType records of name, a fully
+// qualified name, with resolver, or net.DefaultResolver when nil, and builds
+// a DNSRecord from the first answer only, the most preferred exchange for
+// MX, e.g. to copy a single record that currently resolves into Porkbun
+// during a migration. Use RecordsFromLookup for round-robin and other sets.
+// The lookup gives up after DEFAULT_LOOKUP_TIMEOUT.
+func RecordFromLookup(name, recordType string, resolver *net.Resolver) (*DNSRecord, error) {
+	records, err := RecordsFromLookup(context.Background(), name, recordType, resolver)
+	if err != nil {
+		return nil, err
+	}
+	return records[0], nil
+}
+
+// RecordsFromLookup resolves the recordType records of name, a fully
+// qualified name, with resolver, or net.DefaultResolver when nil, and builds
+// one DNSRecord per answer, in the order the resolver returned them. MX
+// records keep each exchange's preference as Prio. The resolver does not
+// report TTLs, so TTL is left for Porkbun's default. Without a deadline on
+// ctx the lookup gives up after DEFAULT_LOOKUP_TIMEOUT. The types
+// CheckPropagation supports are supported; a lookup without answers is an
+// error.
+func RecordsFromLookup(ctx context.Context, name, recordType string, resolver *net.Resolver) ([]*DNSRecord, error) {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	ctx, cancel := withTimeout(ctx, DEFAULT_LOOKUP_TIMEOUT)
+	defer cancel()
+	fqdn := strings.TrimSuffix(name, ".")
+	typ := strings.ToUpper(recordType)
+	var answers, prios []string
+	var err error
+	switch typ {
+	case "MX":
+		var mxs []*net.MX
+		if mxs, err = resolver.LookupMX(ctx, asciiName(fqdn)); err == nil {
+			for _, mx := range mxs {
+				answers = append(answers, mx.Host)
+				prios = append(prios, strconv.Itoa(int(mx.Pref)))
+			}
+		}
+	case "A", "AAAA", "CNAME", "NS", "TXT":
+		answers, err = lookup(ctx, resolver, asciiName(fqdn), typ)
+	default:
+		return nil, fmt.Errorf("lookups do not support %s records", recordType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("looking up %s %s: %w", typ, fqdn, err)
+	}
+	// LookupCNAME answers with name itself when there is no CNAME.
+	if typ == "CNAME" && len(answers) > 0 && strings.EqualFold(strings.TrimSuffix(answers[0], "."), asciiName(fqdn)) {
+		answers = nil
+	}
+	if len(answers) == 0 {
+		return nil, fmt.Errorf("looking up %s %s: no records found", typ, fqdn)
+	}
+	records := make([]*DNSRecord, len(answers))
+	for i, answer := range answers {
+		record := &DNSRecord{Name: fqdn, Type: typ, Content: answer}
+		switch typ {
+		case "CNAME", "MX", "NS":
+			record.Content = strings.TrimSuffix(record.Content, ".")
+		}
+		if prios != nil {
+			record.Prio = prios[i]
+		}
+		records[i] = record
+	}
+	return records, nil
+}
@@ -0,0 +1,107 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// answerResolver returns a resolver answering queries of each type in
+// answers with one record per rdata, and other queries with no records,
+// through fakeResolver.
+func answerResolver(t *testing.T, answers map[uint16][][]byte) *net.Resolver {
+	addr := fakeResolver(t, func(query []byte) []byte {
+		qend, _ := skipDNSName(query, 12)
+		rdata := answers[uint16(query[qend])<<8|uint16(query[qend+1])]
+		msg := []byte{query[0], query[1], 0x81, 0x80, 0, 1, 0, byte(len(rdata)), 0, 0, 0, 0}
+		msg = append(msg, query[12:qend+4]...)
+		for _, r := range rdata {
+			msg = append(msg, 0xc0, 12, query[qend], query[qend+1], 0, 1, 0, 0, 0, 60, 0, byte(len(r)))
+			msg = append(msg, r...)
+		}
+		return msg
+	})
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", addr)
+		},
+	}
+}
+
+func TestRecordsFromLookup(t *testing.T) {
+	mx := func(pref byte, host string) []byte {
+		r := []byte{0, pref}
+		for _, label := range []string{host, "example", "com"} {
+			r = append(append(r, byte(len(label))), label...)
+		}
+		return append(r, 0)
+	}
+	resolver := answerResolver(t, map[uint16][][]byte{
+		1:  {{192, 0, 2, 1}, {192, 0, 2, 2}},
+		15: {mx(10, "mx1"), mx(20, "mx2")},
+	})
+	ctx := context.Background()
+
+	records, err := RecordsFromLookup(ctx, "www.example.com.", "a", resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*DNSRecord{
+		{Name: "www.example.com", Type: "A", Content: "192.0.2.1"},
+		{Name: "www.example.com", Type: "A", Content: "192.0.2.2"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("A records = %+v, want both addresses", records)
+	}
+
+	records, err = RecordsFromLookup(ctx, "example.com", "MX", resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []*DNSRecord{
+		{Name: "example.com", Type: "MX", Content: "mx1.example.com", Prio: "10"},
+		{Name: "example.com", Type: "MX", Content: "mx2.example.com", Prio: "20"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("MX records = %+v, want both exchanges", records)
+	}
+	record, err := RecordFromLookup("example.com", "MX", resolver)
+	if err != nil || !reflect.DeepEqual(record, want[0]) {
+		t.Errorf("RecordFromLookup = %+v, %v, want the most preferred exchange", record, err)
+	}
+
+	if _, err := RecordsFromLookup(ctx, "www.example.com", "AAAA", resolver); err == nil {
+		t.Error("AAAA without answers: got no error")
+	}
+}
+
+func TestRecordsFromLookupHonorsContext(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	silent := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "udp", conn.LocalAddr().String())
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = RecordsFromLookup(ctx, "www.example.com", "A", silent)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("returned after %v, want soon after the deadline", elapsed)
+	}
+	var dnsErr *net.DNSError
+	if !errors.As(err, &dnsErr) || !dnsErr.IsTimeout {
+		t.Errorf("err = %v, want a DNS timeout", err)
+	}
+}
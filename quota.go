@@ -0,0 +1,85 @@
+package porkbun
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Quota is the rate-limit state reported by the last response carrying
+// rate-limit headers (X-RateLimit-Limit, X-RateLimit-Remaining,
+// X-RateLimit-Reset) or a Retry-After header. Porkbun does not document
+// such headers, so Known may stay false; Retry-After is still captured on
+// 429 responses.
+type Quota struct {
+	// Known is set once any of the headers has been seen.
+	Known bool
+	// Limit and Remaining are -1 when the response did not report them.
+	Limit     int
+	Remaining int
+	// Reset is when the window resets, zero if not reported.
+	Reset time.Time
+	// RetryAfter is how long the server asked the client to wait.
+	RetryAfter time.Duration
+	// Observed is when the response was received.
+	Observed time.Time
+}
+
+// LastQuota returns the rate-limit state of the most recent response that
+// reported one.
+func (c *Client) LastQuota() Quota {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	return c.quota
+}
+
+func (c *Client) recordQuota(res *http.Response) {
+	quota, ok := parseQuota(res.Header, c.clock().Now())
+	if !ok {
+		return
+	}
+	c.statsMu.Lock()
+	c.quota = quota
+	c.statsMu.Unlock()
+}
+
+func parseQuota(h http.Header, now time.Time) (Quota, bool) {
+	q := Quota{Limit: -1, Remaining: -1, Observed: now}
+	if n, err := strconv.Atoi(h.Get("X-RateLimit-Limit")); err == nil {
+		q.Limit, q.Known = n, true
+	}
+	if n, err := strconv.Atoi(h.Get("X-RateLimit-Remaining")); err == nil {
+		q.Remaining, q.Known = n, true
+	}
+	if n, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		// Either a Unix timestamp or a number of seconds from now.
+		if n > 1e9 {
+			q.Reset = time.Unix(n, 0)
+		} else {
+			q.Reset = now.Add(time.Duration(n) * time.Second)
+		}
+		q.Known = true
+	}
+	if d, ok := parseRetryAfter(h.Get("Retry-After"), now); ok {
+		q.RetryAfter, q.Known = d, true
+	}
+	return q, q.Known
+}
+
+// parseRetryAfter parses a Retry-After value given in seconds or as an HTTP
+// date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+		return time.Duration(n) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,55 @@
+package porkbun
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket admitting perSecond requests per second
+// with bursts of up to burst requests. Tokens may go negative: each waiter
+// reserves the next free slot, so concurrent callers queue up fairly.
+type rateLimiter struct {
+	clock     Clock
+	mu        sync.Mutex
+	perSecond float64
+	burst     float64
+	tokens    float64
+	last      time.Time
+}
+
+func newRateLimiter(perSecond float64, clock Clock) *rateLimiter {
+	burst := math.Max(1, math.Ceil(perSecond))
+	return &rateLimiter{clock: clock, perSecond: perSecond, burst: burst, tokens: burst, last: clock.Now()}
+}
+
+// wait blocks until the request may be sent. When ctx is done first, the
+// reserved slot is given back and an error wrapping ctx.Err() is returned
+// right away. A nil limiter never waits.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	now := l.clock.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.perSecond)
+	l.last = now
+	l.tokens--
+	delay := time.Duration(-l.tokens / l.perSecond * float64(time.Second))
+	l.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	if err := sleep(ctx, l.clock, delay); err != nil {
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return fmt.Errorf("waiting for rate limiter: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterCancelMidWait(t *testing.T) {
+	s, c := newTestServer(t, &Config{RequestsPerSecond: 0.2}, respond(http.StatusOK, `{"status":"SUCCESS","domains":[]}`))
+	if _, err := c.ListAllDomains(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The next slot is five seconds away; cancel well before it.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := c.ListAllDomains(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("returned after %v, want promptly after the cancel", elapsed)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want one wrapping context.Canceled", err)
+	}
+	if n := len(s.Requests()); n != 1 {
+		t.Errorf("server saw %d requests, want the cancelled one not sent", n)
+	}
+
+	// The cancelled wait gave its slot back, so the limiter is not pushed
+	// further out than one interval.
+	c.limiter.mu.Lock()
+	tokens := c.limiter.tokens
+	c.limiter.mu.Unlock()
+	if tokens < -0.1 {
+		t.Errorf("tokens = %v after the cancel, want the slot returned", tokens)
+	}
+}
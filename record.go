@@ -0,0 +1,370 @@
+package porkbun
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record types accepted by the Porkbun DNS API.
+var knownRecordTypes = map[string]bool{
+	"A":     true,
+	"AAAA":  true,
+	"ALIAS": true,
+	"CAA":   true,
+	"CNAME": true,
+	"HTTPS": true,
+	"MX":    true,
+	"NS":    true,
+	"SRV":   true,
+	"SVCB":  true,
+	"TLSA":  true,
+	"TXT":   true,
+}
+
+// UnmarshalJSON decodes a record, dropping the "0" prio Porkbun reports for
+// types that have no priority. An A record therefore never carries a
+// spurious prio, while an MX or SRV record with an explicit priority of 0
+// keeps Prio "0" and round-trips unchanged. TXT content is unquoted, see
+// unquoteTXT.
+func (r *DNSRecord) UnmarshalJSON(data []byte) error {
+	type plain DNSRecord
+	if err := json.Unmarshal(data, (*plain)(r)); err != nil {
+		return err
+	}
+	if r.Prio == "0" && !hasPriority(r.Type) {
+		r.Prio = ""
+	}
+	if strings.EqualFold(r.Type, "TXT") {
+		r.Content = unquoteTXT(r.Content)
+	}
+	return nil
+}
+
+// unquoteTXT returns TXT content in the canonical unquoted form used by this
+// package, leaving quoting and escaping to the API. Content consisting only
+// of quoted strings, such as "v=spf1 -all" or a DKIM key split into
+// "..." "...", is unquoted and joined; anything else is returned as is.
+func unquoteTXT(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if len(trimmed) < 2 || trimmed[0] != '"' || trimmed[len(trimmed)-1] != '"' {
+		return content
+	}
+	var b strings.Builder
+	for _, field := range splitZoneFields(trimmed) {
+		if len(field) < 2 || field[0] != '"' || field[len(field)-1] != '"' {
+			return content
+		}
+		b.WriteString(unquoteZoneString(field))
+	}
+	return b.String()
+}
+
+// hasPriority reports whether records of the type use the prio field.
+func hasPriority(recordType string) bool {
+	switch strings.ToUpper(recordType) {
+	case "MX", "SRV":
+		return true
+	}
+	return false
+}
+
+// Validate checks the record locally before it is sent to the API: the type
+// must be known, TTL and Prio must be numeric when set, and Content must be
+// shaped correctly for the type.
+func (r *DNSRecord) Validate() error {
+	typ := strings.ToUpper(r.Type)
+	if typ == "" {
+		return fmt.Errorf("record type must not be empty")
+	}
+	if !knownRecordTypes[typ] {
+		return fmt.Errorf("unknown record type %q", r.Type)
+	}
+	if r.TTL != "" {
+		if _, err := strconv.ParseUint(r.TTL, 10, 32); err != nil {
+			return fmt.Errorf("record ttl must be a non-negative integer, got %q", r.TTL)
+		}
+	}
+	if r.Prio != "" {
+		if _, err := strconv.ParseUint(r.Prio, 10, 16); err != nil {
+			return fmt.Errorf("record prio must be an integer between 0 and 65535, got %q", r.Prio)
+		}
+	}
+	if r.Content == "" {
+		return fmt.Errorf("%s record content must not be empty", typ)
+	}
+	return validateContent(typ, r.Content)
+}
+
+func validateContent(typ string, content string) error {
+	switch typ {
+	case "A":
+		if ip := net.ParseIP(content); ip == nil || ip.To4() == nil || strings.Contains(content, ":") {
+			return fmt.Errorf("A record content must be a valid IPv4 address")
+		}
+	case "AAAA":
+		if ip := net.ParseIP(content); ip == nil || !strings.Contains(content, ":") {
+			return fmt.Errorf("AAAA record content must be a valid IPv6 address")
+		}
+	case "CNAME", "ALIAS", "NS", "MX":
+		if !isHostname(content) {
+			return fmt.Errorf("%s record content must be a valid hostname", typ)
+		}
+	case "SRV":
+		// Porkbun carries the SRV priority in Prio, leaving "weight port target".
+		fields := strings.Fields(content)
+		if len(fields) != 3 || !isUint16(fields[0]) || !isUint16(fields[1]) || !isHostname(fields[2]) {
+			return fmt.Errorf("SRV record content must be \"weight port target\"")
+		}
+	case "HTTPS", "SVCB":
+		if _, err := ParseSVCB(content); err != nil {
+			return fmt.Errorf("%s record content is invalid: %w", typ, err)
+		}
+	}
+	return nil
+}
+
+func isUint16(s string) bool {
+	_, err := strconv.ParseUint(s, 10, 16)
+	return err == nil
+}
+
+// isHostname reports whether s is a syntactically valid host name, with or
+// without a trailing dot. Underscores are allowed for service labels.
+func isHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 {
+			return false
+		}
+		if label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, ch := range label {
+			if !(ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9' || ch == '-' || ch == '_') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// normalizeRecord returns a copy of r in the form sent to the API.
+//
+// Hostname targets of CNAME, ALIAS, MX and NS records are sent without a
+// trailing dot, which is how Porkbun stores and returns them, so "example.com."
+// and "example.com" produce the same record.
+//
+// Leading and trailing whitespace, usually left over from copy and paste,
+// is trimmed from Content, and TXT content is then sent unquoted, see
+// unquoteTXT; whitespace inside quotes is kept.
+//
+// A TTL of "0" means the server default, like an empty TTL, and is left out
+// of the request so Porkbun applies its default.
+func normalizeRecord(r DNSRecord) DNSRecord {
+	r.Content = strings.TrimSpace(r.Content)
+	switch strings.ToUpper(r.Type) {
+	case "CNAME", "ALIAS", "MX", "NS":
+		r.Content = strings.TrimSuffix(r.Content, ".")
+	case "TXT":
+		r.Content = unquoteTXT(r.Content)
+	}
+	if r.TTL == "0" {
+		r.TTL = ""
+	}
+	return r
+}
+
+// FQDN returns the fully qualified name of the record within domain, without
+// a trailing dot. Name may be relative ("www", "*", "" or "@" for the apex)
+// or already qualified as returned by the retrieve endpoint.
+func (r *DNSRecord) FQDN(domain string) string {
+	domain = strings.TrimSuffix(domain, ".")
+	name := relativeName(r.Name, domain)
+	if name == "" || name == "@" {
+		return domain
+	}
+	return name + "." + domain
+}
+
+// relativeName strips domain from a fully qualified record name, so
+// "www.example.com" becomes "www" and "example.com" becomes "". Names that
+// are already relative are returned unchanged. Internationalized names are
+// compared, and returned, in punycode.
+func relativeName(name string, domain string) string {
+	if subdomain, ok := SplitName(name, domain); ok {
+		return subdomain
+	}
+	return asciiName(strings.TrimSuffix(name, "."))
+}
+
+// SplitName separates a fully qualified record name, as the retrieve
+// endpoint returns it, into the subdomain create and edit expect: "" for the
+// apex, "*" for "*.example.com" and "www" for "www.example.com". Matching
+// is case-insensitive and ignores trailing dots. ok is false when fullName
+// does not lie within domain, in which case subdomain is empty.
+func SplitName(fullName string, domain string) (subdomain string, ok bool) {
+	name := asciiName(strings.TrimSuffix(fullName, "."))
+	zone := asciiName(strings.TrimSuffix(domain, "."))
+	if zone == "" {
+		return "", false
+	}
+	if strings.EqualFold(name, zone) {
+		return "", true
+	}
+	if suffix := "." + zone; len(name) > len(suffix) && strings.EqualFold(name[len(name)-len(suffix):], suffix) {
+		return name[:len(name)-len(suffix)], true
+	}
+	return "", false
+}
+
+// nameKey is the form record names are matched in: relative to domain,
+// lowercased, without a trailing dot, and with "@" meaning the apex like "".
+// DNS names are case-insensitive and Porkbun may return another casing than
+// was sent.
+func nameKey(name string, domain string) string {
+	key := strings.ToLower(relativeName(name, domain))
+	if key == "@" {
+		return ""
+	}
+	return key
+}
+
+// sameRecord reports whether a and b have the same name, type, content, TTL
+// and prio once normalized. Names may be relative or fully qualified.
+func sameRecord(domain string, a *DNSRecord, b *DNSRecord) bool {
+	x, y := normalizeRecord(*a), normalizeRecord(*b)
+	return nameKey(x.Name, domain) == nameKey(y.Name, domain) &&
+		strings.EqualFold(x.Type, y.Type) &&
+		x.Content == y.Content &&
+		x.TTL == y.TTL &&
+		x.Prio == y.Prio
+}
+
+// SortRecords orders records by name, type and content, then prio, TTL and
+// ID, so listings, backups and diffs are reproducible whatever order the API
+// returned them in. Names compare case-insensitively.
+func SortRecords(records []*DNSRecord) {
+	key := func(r *DNSRecord) []string {
+		return []string{strings.ToLower(strings.TrimSuffix(r.Name, ".")), strings.ToUpper(r.Type), r.Content, r.Prio, r.TTL, r.ID}
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := key(records[i]), key(records[j])
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+}
+
+// canonicalRecord is normalizeRecord with the name lowercased and without a
+// trailing dot and the type uppercased, the form hashes are computed over.
+func canonicalRecord(r DNSRecord) DNSRecord {
+	r = normalizeRecord(r)
+	r.Name = strings.ToLower(strings.TrimSuffix(r.Name, "."))
+	r.Type = strings.ToUpper(r.Type)
+	return r
+}
+
+// Fingerprint returns a hex SHA-256 over the record's name, type, content,
+// TTL and prio in canonical form, so a record retrieved twice without
+// changes has the same fingerprint however it was formatted. ID and notes
+// are left out. A relative and a fully qualified name give different
+// fingerprints; compare records retrieved the same way.
+func (r *DNSRecord) Fingerprint() string {
+	c := canonicalRecord(*r)
+	h := sha256.New()
+	for _, field := range []string{c.Name, c.Type, c.Content, c.TTL, c.Prio} {
+		io.WriteString(h, strconv.Quote(field))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// PROPAGATION_WARNING_TTL is the TTL in seconds from which PropagationWarning
+// warns that a change takes long to be seen everywhere.
+const PROPAGATION_WARNING_TTL = 3600
+
+// PropagationWarning returns a note for users about to change the record when
+// its TTL is at least PROPAGATION_WARNING_TTL, such as
+//
+//	this record has a TTL of 24h; resolvers may keep serving the old value for that long after a change
+//
+// and "" otherwise, including for an empty or invalid TTL.
+func (r *DNSRecord) PropagationWarning() string {
+	ttl, err := strconv.Atoi(r.TTL)
+	if err != nil || ttl < PROPAGATION_WARNING_TTL {
+		return ""
+	}
+	return fmt.Sprintf("this record has a TTL of %s; resolvers may keep serving the old value for that long after a change", formatTTL(ttl))
+}
+
+// formatTTL renders seconds compactly, e.g. "24h", "1h30m" or "1m30s".
+func formatTTL(seconds int) string {
+	s := (time.Duration(seconds) * time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = s[:len(s)-2]
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = s[:len(s)-2]
+	}
+	return s
+}
+
+// ParseRecordSpec parses the compact key=value form CLIs take records in,
+// e.g.
+//
+//	type=A name=www content=1.2.3.4 ttl=600
+//	type=TXT name=@ content="v=spf1 include:_spf.porkbun.com ~all"
+//
+// The keys are type, name, content, ttl, prio and notes, each at most once;
+// values containing spaces are double-quoted, and name "@" or a missing
+// name means the apex. The record is checked with
+// Validate before it is returned.
+func ParseRecordSpec(spec string) (*DNSRecord, error) {
+	record := &DNSRecord{}
+	fields := map[string]*string{
+		"type":    &record.Type,
+		"name":    &record.Name,
+		"content": &record.Content,
+		"ttl":     &record.TTL,
+		"prio":    &record.Prio,
+		"notes":   &record.Notes,
+	}
+	seen := make(map[string]bool)
+	for _, field := range splitZoneFields(spec) {
+		i := strings.IndexByte(field, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("record spec field %q is not key=value", field)
+		}
+		key := strings.ToLower(field[:i])
+		value, ok := fields[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown record spec key %q", field[:i])
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("record spec key %q given twice", key)
+		}
+		seen[key] = true
+		*value = unquoteZoneString(field[i+1:])
+	}
+	record.Type = strings.ToUpper(record.Type)
+	if record.Name == "@" {
+		record.Name = ""
+	}
+	if err := record.Validate(); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
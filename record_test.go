@@ -0,0 +1,111 @@
+package porkbun
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestUnmarshalPrioZero(t *testing.T) {
+	for _, tc := range []struct {
+		json string
+		want string
+	}{
+		{`{"type":"A","content":"1.1.1.1","prio":"0"}`, ""},
+		{`{"type":"CNAME","content":"example.net","prio":"0"}`, ""},
+		{`{"type":"MX","content":"mx.example.com","prio":"0"}`, "0"},
+		{`{"type":"SRV","content":"5 443 sip.example.com","prio":"0"}`, "0"},
+		{`{"type":"MX","content":"mx.example.com","prio":"10"}`, "10"},
+	} {
+		var record DNSRecord
+		if err := json.Unmarshal([]byte(tc.json), &record); err != nil {
+			t.Fatal(err)
+		}
+		if record.Prio != tc.want {
+			t.Errorf("%s: prio %q, want %q", tc.json, record.Prio, tc.want)
+		}
+		data, err := json.Marshal(&record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back DNSRecord
+		if err := json.Unmarshal(data, &back); err != nil {
+			t.Fatal(err)
+		}
+		if back != record {
+			t.Errorf("%s: round trip gave %+v, want %+v", tc.json, back, record)
+		}
+	}
+}
+
+func TestNameKeyMixedCase(t *testing.T) {
+	for _, tc := range []struct{ name, domain, want string }{
+		{"WWW.Example.COM", "example.com", "www"},
+		{"www.example.com.", "Example.com.", "www"},
+		{"Example.COM", "example.com", ""},
+		{"@", "example.com", ""},
+		{"Mail", "example.com", "mail"},
+		{"_DMARC.example.com", "EXAMPLE.COM", "_dmarc"},
+	} {
+		if got := nameKey(tc.name, tc.domain); got != tc.want {
+			t.Errorf("nameKey(%q, %q) = %q, want %q", tc.name, tc.domain, got, tc.want)
+		}
+	}
+	a := &DNSRecord{Name: "WWW.Example.COM.", Type: "a", Content: "1.1.1.1"}
+	b := &DNSRecord{Name: "www", Type: "A", Content: "1.1.1.1"}
+	if !sameRecord("example.com", a, b) {
+		t.Error("records differing only in name case are not the same")
+	}
+}
+
+func TestTXTQuoting(t *testing.T) {
+	const dkim = "v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC"
+	cases := map[string]string{
+		`v=spf1 include:_spf.porkbun.com ~all`:   `v=spf1 include:_spf.porkbun.com ~all`,
+		`"v=spf1 include:_spf.porkbun.com ~all"`: `v=spf1 include:_spf.porkbun.com ~all`,
+		` "v=spf1 -all" `:                        `v=spf1 -all`,
+		dkim:                                     dkim,
+		`"v=DKIM1; k=rsa; " "p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC"`: dkim,
+		`"say \"hi\""`: `say "hi"`,
+		`"a" b`:        `"a" b`,
+	}
+	ts, c := newTestServer(t, nil, respond(http.StatusOK, `{"status":"SUCCESS","id":1}`))
+	for content, want := range cases {
+		if _, err := c.CreateRecord("example.com", &DNSRecord{Type: "TXT", Content: content}); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := ts.last(t).field(t, "content"); got != want {
+			t.Errorf("create with %q sent %q, want %q", content, got, want)
+		}
+		data, _ := json.Marshal(map[string]string{"type": "TXT", "content": content})
+		var retrieved DNSRecord
+		if err := json.Unmarshal(data, &retrieved); err != nil {
+			t.Fatal(err)
+		}
+		if retrieved.Content != want {
+			t.Errorf("retrieved %q as %q, want %q", content, retrieved.Content, want)
+		}
+	}
+}
+
+func TestCreateLowercasesName(t *testing.T) {
+	for _, tt := range []struct {
+		preserve bool
+		want     string
+	}{
+		{false, "www"},
+		{true, "WWW"},
+	} {
+		s, c := newTestServer(t, &Config{PreserveNameCase: tt.preserve}, respond(http.StatusOK, `{"status":"SUCCESS","id":"1"}`))
+		if _, err := c.CreateRecord("example.com", &DNSRecord{Name: "WWW", Type: "CNAME", Content: "Target.Example.net"}); err != nil {
+			t.Fatal(err)
+		}
+		req := s.last(t)
+		if name, _ := req.field(t, "name"); name != tt.want {
+			t.Errorf("PreserveNameCase %t: name = %v, want %s", tt.preserve, name, tt.want)
+		}
+		if content, _ := req.field(t, "content"); content != "Target.Example.net" {
+			t.Errorf("PreserveNameCase %t: content = %v, want its case kept", tt.preserve, content)
+		}
+	}
+}
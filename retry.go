@@ -0,0 +1,144 @@
+package porkbun
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Backoff between retries of a failed call, see Config.MaxRetries.
+const (
+	DEFAULT_RETRY_MIN_INTERVAL = 1 * time.Second
+	DEFAULT_RETRY_MAX_INTERVAL = 30 * time.Second
+)
+
+// sendError is returned when a request could not be sent or no response
+// arrived, as opposed to an error reported by the API.
+type sendError struct {
+	err error
+}
+
+func (e *sendError) Error() string { return "sending request: " + e.err.Error() }
+func (e *sendError) Unwrap() error { return e.err }
+
+// post is send for reads, bounded by Config.ReadTimeout and answered from
+// the cache on 304 Not Modified, see etag.go.
+func (c *Client) post(ctx context.Context, url string, body []byte, out statusResponse) error {
+	ctx, cancel := withTimeout(ctx, c.config.ReadTimeout)
+	defer cancel()
+	return c.send(withConditional(ctx), url, body, out, true)
+}
+
+// withTimeout bounds ctx by timeout unless timeout is zero or ctx already
+// has a deadline of its own.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// send sends body to url and interprets the response into out, retrying
+// failures Config.RetryPolicy deems transient up to Config.MaxRetries times
+// and drawing each retry from the shared retry budget. Every attempt first
+// waits for the rate limiter. Whether a failed request may be sent again at
+// all is up to mayRepeat.
+func (c *Client) send(ctx context.Context, url string, body []byte, out statusResponse, idempotent bool) error {
+	interval := DEFAULT_RETRY_MIN_INTERVAL
+	for attempt := 0; ; attempt++ {
+		if err := c.limiter.wait(ctx); err != nil {
+			return err
+		}
+		res, err := c.postOnce(ctx, url, body, out)
+		if err == nil || attempt >= c.config.MaxRetries || ctx.Err() != nil || !c.retryPolicy()(res, err) ||
+			!c.mayRepeat(idempotent, res, err) || !c.retryBudget.take() {
+			return err
+		}
+		wait := jitter(interval)
+		if errors.Is(err, ErrRateLimited) {
+			if after := c.LastQuota().RetryAfter; after > wait {
+				wait = after
+			}
+		}
+		if sleep(ctx, c.clock(), wait) != nil {
+			return err
+		}
+		if interval *= 2; interval > DEFAULT_RETRY_MAX_INTERVAL {
+			interval = DEFAULT_RETRY_MAX_INTERVAL
+		}
+	}
+}
+
+// mayRepeat reports whether a request that failed with res and err may be
+// sent again. Reads and deletes are idempotent, creates and edits are not:
+// unless idempotent or Config.RetryNonIdempotent is set, only rate-limit
+// rejections and maintenance pages are repeated, as the request then
+// provably had no effect.
+func (c *Client) mayRepeat(idempotent bool, res *http.Response, err error) bool {
+	return idempotent || c.config.RetryNonIdempotent || isRateLimited(res, err) || errors.Is(err, ErrServiceMaintenance)
+}
+
+// isRateLimited reports whether the call was turned away by a rate limit.
+func isRateLimited(res *http.Response, err error) bool {
+	return (res != nil && res.StatusCode == http.StatusTooManyRequests) || errors.Is(err, ErrRateLimited)
+}
+
+// DefaultRetryPolicy retries rate limits (HTTP 429 or a rate-limit
+// message), 5xx responses, maintenance pages and failures to get a response
+// at all. Custom policies can fall back to it.
+func DefaultRetryPolicy(res *http.Response, err error) bool {
+	if err == nil {
+		return false
+	}
+	if res == nil {
+		return true
+	}
+	return res.StatusCode == http.StatusTooManyRequests ||
+		res.StatusCode >= http.StatusInternalServerError ||
+		errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrServiceMaintenance)
+}
+
+func (c *Client) retryPolicy() func(*http.Response, error) bool {
+	if c.config.RetryPolicy != nil {
+		return c.config.RetryPolicy
+	}
+	return DefaultRetryPolicy
+}
+
+// retryBudget is a token bucket holding up to perMinute retries and
+// refilling continuously at that rate.
+type retryBudget struct {
+	clock     Clock
+	mu        sync.Mutex
+	perMinute float64
+	tokens    float64
+	last      time.Time
+}
+
+func newRetryBudget(perMinute int, clock Clock) *retryBudget {
+	return &retryBudget{clock: clock, perMinute: float64(perMinute), tokens: float64(perMinute), last: clock.Now()}
+}
+
+// take consumes a token if one is available. A nil budget always allows
+// the retry.
+func (b *retryBudget) take() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.clock.Now()
+	b.tokens += now.Sub(b.last).Minutes() * b.perMinute
+	if b.tokens > b.perMinute {
+		b.tokens = b.perMinute
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
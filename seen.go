@@ -0,0 +1,87 @@
+package porkbun
+
+import (
+	"strings"
+	"time"
+)
+
+// RecordHistory is what a client with Config.TrackRecordHistory has observed
+// about a record. Porkbun reports no timestamps of its own, so these are
+// the times this client first retrieved or created the record and first saw
+// its current name, type, content, TTL, prio and notes. A change, even one
+// made through this client, is dated when the record is next retrieved.
+type RecordHistory struct {
+	FirstSeen   time.Time
+	LastChanged time.Time
+}
+
+// seenRecord holds the last observed state of a record. A record created by
+// the client is only known by ID until it is first retrieved, since the
+// server fills in fields such as the TTL.
+type seenRecord struct {
+	history RecordHistory
+	record  DNSRecord
+	known   bool
+}
+
+// RecordHistory returns the observed history of the record with the given
+// id, and false if the client has not seen it or tracking is off.
+func (c *Client) RecordHistory(domain string, id string) (RecordHistory, bool) {
+	c.seenMu.Lock()
+	defer c.seenMu.Unlock()
+	seen, ok := c.seen[seenKey(domain, id)]
+	if !ok {
+		return RecordHistory{}, false
+	}
+	return seen.history, true
+}
+
+// observeCreate starts the history of a record the client just created.
+func (c *Client) observeCreate(domain string, id string) {
+	if !c.config.TrackRecordHistory {
+		return
+	}
+	now := c.clock().Now()
+	c.seenMu.Lock()
+	defer c.seenMu.Unlock()
+	if c.seen == nil {
+		c.seen = make(map[string]*seenRecord)
+	}
+	c.seen[seenKey(domain, id)] = &seenRecord{history: RecordHistory{FirstSeen: now, LastChanged: now}}
+}
+
+// observe updates the history of records just retrieved.
+func (c *Client) observe(domain string, records ...*DNSRecord) {
+	if !c.config.TrackRecordHistory {
+		return
+	}
+	now := c.clock().Now()
+	c.seenMu.Lock()
+	defer c.seenMu.Unlock()
+	if c.seen == nil {
+		c.seen = make(map[string]*seenRecord)
+	}
+	for _, record := range records {
+		if record.ID == "" {
+			continue
+		}
+		current := normalizeRecord(*record)
+		current.Name = nameKey(current.Name, domain)
+		key := seenKey(domain, record.ID)
+		seen, ok := c.seen[key]
+		if !ok {
+			c.seen[key] = &seenRecord{history: RecordHistory{FirstSeen: now, LastChanged: now}, record: current, known: true}
+			continue
+		}
+		if !seen.known {
+			seen.record, seen.known = current, true
+		} else if seen.record != current {
+			seen.record = current
+			seen.history.LastChanged = now
+		}
+	}
+}
+
+func seenKey(domain string, id string) string {
+	return strings.ToLower(strings.TrimSuffix(domain, ".")) + " " + id
+}
@@ -0,0 +1,104 @@
+package porkbun
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const PORKBUN_SSL_BASE = PORKBUN_API_BASE + "/ssl"
+const PORKBUN_SSL_RETRIEVE = PORKBUN_SSL_BASE + "/retrieve/%s"
+
+// States of an SSLBundle.
+const (
+	SSL_STATE_READY   = "ready"
+	SSL_STATE_PENDING = "pending"
+)
+
+// SSLBundle is the certificate bundle Porkbun issues for a domain. While the
+// certificate is still being generated the bundle is empty and State
+// reports SSL_STATE_PENDING, so callers can poll until it is ready.
+type SSLBundle struct {
+	Status           string `json:"status,omitempty"`
+	Message          string `json:"message,omitempty"`
+	CertificateChain string `json:"certificatechain,omitempty"`
+	PrivateKey       string `json:"privatekey,omitempty"`
+	PublicKey        string `json:"publickey,omitempty"`
+}
+
+func (b *SSLBundle) status() (string, string) { return b.Status, b.Message }
+
+func (b *SSLBundle) pending(status string, message string) bool {
+	if strings.EqualFold(status, STATUS_SUCCESS) {
+		return false
+	}
+	msg := strings.ToLower(status + " " + message)
+	for _, hint := range []string{"pending", "processing", "not ready", "being generated", "not yet"} {
+		if strings.Contains(msg, hint) {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *SSLBundle) validate() error {
+	if b.pending(b.Status, b.Message) {
+		return nil
+	}
+	if b.CertificateChain == "" || b.PrivateKey == "" {
+		return fmt.Errorf("certificate bundle is empty")
+	}
+	return nil
+}
+
+// State reports whether the certificate is ready or still being issued.
+func (b *SSLBundle) State() string {
+	if b.pending(b.Status, b.Message) {
+		return SSL_STATE_PENDING
+	}
+	return SSL_STATE_READY
+}
+
+// RetrieveSSLBundle returns the certificate bundle of domain. A certificate
+// that is still being issued is not an error: the returned bundle's State is
+// SSL_STATE_PENDING.
+func (c *Client) RetrieveSSLBundle(domain string) (*SSLBundle, error) {
+	if err := requireDomain(domain); err != nil {
+		return nil, err
+	}
+	authjson, err := c.getAuthJson()
+	if err != nil {
+		return nil, err
+	}
+	var bundle SSLBundle
+	if err := c.post(context.Background(), endpoint(PORKBUN_SSL_RETRIEVE, domain), authjson, &bundle); err != nil {
+		return nil, err
+	}
+	return &bundle, nil
+}
+
+// SSLCertExpiry returns the expiry of the leaf certificate Porkbun issued for
+// domain, for expiry monitoring. The private key in the retrieved bundle is
+// discarded straight away and never part of the result or an error.
+func (c *Client) SSLCertExpiry(domain string) (time.Time, error) {
+	bundle, err := c.RetrieveSSLBundle(domain)
+	if err != nil {
+		return time.Time{}, err
+	}
+	bundle.PrivateKey = ""
+	if bundle.State() == SSL_STATE_PENDING {
+		return time.Time{}, fmt.Errorf("certificate for %s is still being issued", domain)
+	}
+	block, _ := pem.Decode([]byte(bundle.CertificateChain))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return time.Time{}, fmt.Errorf("no certificate found in the bundle for %s", domain)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parsing certificate for %s: %w", domain, err)
+	}
+	return cert.NotAfter, nil
+}
@@ -0,0 +1,29 @@
+package porkbun
+
+// LastError returns the error of the most recent API call made through the
+// typed methods, or nil if it succeeded. With calls running concurrently it
+// is simply whichever finished last, which is enough for a health check
+// that polls it from another goroutine.
+func (c *Client) LastError() error {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	return c.lastErr
+}
+
+// LastStatus returns the HTTP status code of the most recent API call, or 0
+// if no call has completed or the last one failed before a response
+// arrived. A successful call answered with 202 Accepted was queued by
+// Porkbun rather than applied. The same concurrency caveat as LastError
+// applies.
+func (c *Client) LastStatus() int {
+	c.statsMu.Lock()
+	defer c.statsMu.Unlock()
+	return c.lastStatus
+}
+
+func (c *Client) recordResult(status int, err error) {
+	c.statsMu.Lock()
+	c.lastStatus = status
+	c.lastErr = err
+	c.statsMu.Unlock()
+}
@@ -0,0 +1,189 @@
+package porkbun
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SVCBRecord is the parsed content of an SVCB or HTTPS record (RFC 9460):
+// "priority target key=value ...". Priority 0 is AliasMode, which takes no
+// parameters; Target "." means the owner name itself.
+type SVCBRecord struct {
+	Priority uint16
+	Target   string
+	Params   []SVCParam
+}
+
+// SVCParam is a single service parameter such as alpn=h3,h2. Value is empty
+// for keys without a value, like no-default-alpn.
+type SVCParam struct {
+	Key   string
+	Value string
+}
+
+// Registered service parameter keys and their numbers, which define the
+// order parameters are written in.
+var svcParamKeys = map[string]int{
+	"mandatory":       0,
+	"alpn":            1,
+	"no-default-alpn": 2,
+	"port":            3,
+	"ipv4hint":        4,
+	"ech":             5,
+	"ipv6hint":        6,
+}
+
+// ParseSVCB parses the content of an SVCB or HTTPS record and validates its
+// parameters.
+func ParseSVCB(content string) (*SVCBRecord, error) {
+	fields := splitZoneFields(content)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("SVCB content must be \"priority target [params]\"")
+	}
+	priority, err := strconv.ParseUint(fields[0], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("SVCB priority must be an integer between 0 and 65535, got %q", fields[0])
+	}
+	rr := &SVCBRecord{Priority: uint16(priority), Target: fields[1]}
+	for _, field := range fields[2:] {
+		key, value := field, ""
+		if i := strings.IndexByte(field, '='); i >= 0 {
+			key, value = field[:i], unquoteZoneString(field[i+1:])
+		}
+		rr.Params = append(rr.Params, SVCParam{Key: strings.ToLower(key), Value: value})
+	}
+	if err := rr.Validate(); err != nil {
+		return nil, err
+	}
+	return rr, nil
+}
+
+// Validate checks the target and parameters: AliasMode takes none, keys
+// must be known and unique, mandatory keys present, and port, alpn and the
+// address hints well formed.
+func (rr *SVCBRecord) Validate() error {
+	if rr.Target != "." && !isHostname(rr.Target) {
+		return fmt.Errorf("SVCB target must be a hostname or \".\", got %q", rr.Target)
+	}
+	if rr.Priority == 0 && len(rr.Params) > 0 {
+		return fmt.Errorf("SVCB records with priority 0 (AliasMode) take no parameters")
+	}
+	seen := make(map[string]bool)
+	for _, p := range rr.Params {
+		if _, err := svcParamNumber(p.Key); err != nil {
+			return err
+		}
+		if seen[p.Key] {
+			return fmt.Errorf("SVCB parameter %s appears more than once", p.Key)
+		}
+		seen[p.Key] = true
+		if err := validateSVCParam(p); err != nil {
+			return err
+		}
+	}
+	for _, p := range rr.Params {
+		if p.Key != "mandatory" {
+			continue
+		}
+		for _, key := range strings.Split(p.Value, ",") {
+			if !seen[key] {
+				return fmt.Errorf("SVCB parameter %s is listed as mandatory but missing", key)
+			}
+		}
+	}
+	return nil
+}
+
+// Content renders the record in presentation format, with parameters in
+// ascending key order and values quoted where needed.
+func (rr *SVCBRecord) Content() (string, error) {
+	if err := rr.Validate(); err != nil {
+		return "", err
+	}
+	params := append([]SVCParam(nil), rr.Params...)
+	sort.SliceStable(params, func(i, j int) bool {
+		a, _ := svcParamNumber(params[i].Key)
+		b, _ := svcParamNumber(params[j].Key)
+		return a < b
+	})
+	parts := []string{strconv.Itoa(int(rr.Priority)), rr.Target}
+	for _, p := range params {
+		if p.Value == "" {
+			parts = append(parts, p.Key)
+			continue
+		}
+		parts = append(parts, p.Key+"="+quoteSVCValue(p.Value))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// NewSVCBRecord builds an SVCB record at name from rr.
+func NewSVCBRecord(name string, rr *SVCBRecord) (*DNSRecord, error) {
+	return newServiceRecord("SVCB", name, rr)
+}
+
+// NewHTTPSRecord builds an HTTPS record at name from rr.
+func NewHTTPSRecord(name string, rr *SVCBRecord) (*DNSRecord, error) {
+	return newServiceRecord("HTTPS", name, rr)
+}
+
+func newServiceRecord(recordType string, name string, rr *SVCBRecord) (*DNSRecord, error) {
+	content, err := rr.Content()
+	if err != nil {
+		return nil, err
+	}
+	return &DNSRecord{Name: name, Type: recordType, Content: content}, nil
+}
+
+// svcParamNumber returns the key number of a registered key or of a generic
+// "keyNNNNN" key.
+func svcParamNumber(key string) (int, error) {
+	if n, ok := svcParamKeys[key]; ok {
+		return n, nil
+	}
+	if strings.HasPrefix(key, "key") {
+		if n, err := strconv.ParseUint(key[3:], 10, 16); err == nil {
+			return int(n), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown SVCB parameter %q", key)
+}
+
+func validateSVCParam(p SVCParam) error {
+	switch p.Key {
+	case "no-default-alpn":
+		if p.Value != "" {
+			return fmt.Errorf("SVCB parameter no-default-alpn takes no value")
+		}
+		return nil
+	case "port":
+		if !isUint16(p.Value) {
+			return fmt.Errorf("SVCB port must be an integer between 0 and 65535, got %q", p.Value)
+		}
+		return nil
+	case "ipv4hint", "ipv6hint":
+		for _, addr := range strings.Split(p.Value, ",") {
+			ip := net.ParseIP(addr)
+			if ip == nil || (p.Key == "ipv4hint") != (ip.To4() != nil && !strings.Contains(addr, ":")) {
+				return fmt.Errorf("SVCB %s contains an invalid address %q", p.Key, addr)
+			}
+		}
+		return nil
+	}
+	if p.Value == "" {
+		return fmt.Errorf("SVCB parameter %s needs a value", p.Key)
+	}
+	return nil
+}
+
+// quoteSVCValue quotes a value containing characters that would otherwise
+// end it.
+func quoteSVCValue(v string) string {
+	if !strings.ContainsAny(v, " \t\";()") {
+		return v
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(v) + `"`
+}
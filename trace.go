@@ -0,0 +1,50 @@
+package porkbun
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+)
+
+// ENV_DEBUG names a file that clients created while it is set append every
+// request and response to, for attaching to support tickets. The API key
+// and secret and the private key of SSL bundles are always masked, and
+// Config.Redactor is applied on top. Tracing is off when the variable is
+// unset.
+const ENV_DEBUG = "PORKBUN_DEBUG"
+
+// trace appends a request and its response, or the error that prevented
+// one, to the debug file. The response body is read into memory up to the
+// size limit and put back so the caller sees it unchanged. Failures to
+// write the trace are ignored.
+func (c *Client) trace(url string, body []byte, res *http.Response, err error) {
+	if c.tracePath == "" {
+		return
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "--- %s %s %s\n> %s\n", c.clock().Now().UTC().Format(time.RFC3339Nano), PORKBUN_HTTP_METHOD, url, body)
+	if err != nil {
+		fmt.Fprintf(&b, "< error: %v\n", err)
+	} else {
+		data, _ := io.ReadAll(io.LimitReader(res.Body, c.maxResponseBytes()+1))
+		res.Body = readCloser{io.MultiReader(bytes.NewReader(data), res.Body), res.Body}
+		fmt.Fprintf(&b, "< %s\n< %s\n", res.Status, data)
+	}
+	c.traceMu.Lock()
+	defer c.traceMu.Unlock()
+	f, ferr := os.OpenFile(c.tracePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if ferr != nil {
+		return
+	}
+	defer f.Close()
+	io.WriteString(f, c.redact(c.maskSecrets(b.String())))
+}
+
+// readCloser reads from one reader and closes another.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,121 @@
+package porkbun
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Transaction performs record changes while remembering how to undo them,
+// so a batch that fails halfway can be rolled back with Rollback. The API
+// has no transactions, so this is best effort: changes made by others in
+// the meantime are not detected, and a deleted record comes back with a new
+// ID. A Transaction is safe for concurrent use.
+type Transaction struct {
+	c *Client
+
+	mu   sync.Mutex
+	undo []Operation
+}
+
+// Begin starts a Transaction on the client.
+func (c *Client) Begin() *Transaction {
+	return &Transaction{c: c}
+}
+
+// CreateRecord creates record like Client.CreateRecord; rolling back
+// deletes it.
+func (t *Transaction) CreateRecord(domain string, record *DNSRecord) (string, error) {
+	id, err := t.c.CreateRecord(domain, record)
+	if err != nil {
+		return "", err
+	}
+	t.record(Operation{Op: AUDIT_OP_DELETE, Domain: domain, ID: id})
+	return id, nil
+}
+
+// EditRecord edits the record like Client.EditRecord; rolling back edits it
+// back to its state before, which is retrieved first.
+func (t *Transaction) EditRecord(domain string, id string, record *DNSRecord) error {
+	before, err := t.c.RetrieveRecord(domain, id)
+	if err != nil {
+		return err
+	}
+	if err := t.c.EditRecord(domain, id, record); err != nil {
+		return err
+	}
+	t.record(Operation{Op: AUDIT_OP_EDIT, Domain: domain, ID: id, Record: before})
+	return nil
+}
+
+// DeleteRecord deletes the record like Client.DeleteRecord; rolling back
+// creates it again from its state before, which is retrieved first.
+func (t *Transaction) DeleteRecord(domain string, id string) error {
+	before, err := t.c.RetrieveRecord(domain, id)
+	if err != nil {
+		return err
+	}
+	if err := t.c.DeleteRecord(domain, id); err != nil {
+		return err
+	}
+	t.record(Operation{Op: AUDIT_OP_CREATE, Domain: domain, ID: id, Record: before})
+	return nil
+}
+
+// Rollback undoes the changes made through the transaction, newest first,
+// and forgets them. Every inverse is attempted even when an earlier one
+// fails; the results list them in the order run, with the ID of a recreated
+// record being its new one, and the error reports how many failed.
+func (t *Transaction) Rollback() ([]BulkResult, error) {
+	t.mu.Lock()
+	undo := t.undo
+	t.undo = nil
+	t.mu.Unlock()
+
+	ctx := context.Background()
+	// Records recreated by an earlier inverse, by their original ID, so
+	// inverses of older edits reach the new record.
+	recreated := make(map[string]string)
+	results := make([]BulkResult, 0, len(undo))
+	failed := 0
+	for i := len(undo) - 1; i >= 0; i-- {
+		op := undo[i]
+		if id, ok := recreated[op.ID]; ok {
+			op.ID = id
+		}
+		var id string
+		var err error
+		switch op.Op {
+		case AUDIT_OP_CREATE:
+			id, err = t.c.createRecord(ctx, op.Domain, op.Record)
+			if err == nil {
+				recreated[op.ID] = id
+			}
+		case AUDIT_OP_EDIT:
+			id, err = op.ID, t.c.editRecordNotes(ctx, op.Domain, op.ID, op.Record, true)
+		default:
+			id, err = t.c.runOperation(ctx, op)
+		}
+		if err != nil {
+			failed++
+		}
+		results = append(results, BulkResult{ID: id, Record: op.Record, Err: err})
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("rollback: %d of %d operations failed", failed, len(results))
+	}
+	return results, nil
+}
+
+// Pending returns the inverse operations Rollback would run, oldest first.
+func (t *Transaction) Pending() []Operation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Operation(nil), t.undo...)
+}
+
+func (t *Transaction) record(op Operation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.undo = append(t.undo, op)
+}
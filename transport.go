@@ -0,0 +1,55 @@
+package porkbun
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+)
+
+type httpClientKey struct{}
+
+// WithHTTPClient returns a context that makes the client send its requests
+// with hc instead of the configured HTTP client, e.g. to give a bulk
+// retrieve of a huge zone a longer timeout. It applies to the methods taking
+// a context, such as RetrieveRecordsMulti, ApplyZone and Do.
+func WithHTTPClient(ctx context.Context, hc *http.Client) context.Context {
+	return context.WithValue(ctx, httpClientKey{}, hc)
+}
+
+// httpClient returns the HTTP client for a request made with ctx.
+func (c *Client) httpClient(ctx context.Context) *http.Client {
+	if hc, ok := ctx.Value(httpClientKey{}).(*http.Client); ok && hc != nil {
+		return hc
+	}
+	return c.config.Client
+}
+
+// newHTTPClient returns http.DefaultClient unless the config asks for a
+// timeout, a custom transport or transport tuning, in which case a dedicated
+// client is built.
+func newHTTPClient(cfg *Config) *http.Client {
+	if cfg.Timeout == 0 && cfg.Transport == nil && cfg.MaxIdleConnsPerHost == 0 && !cfg.DisableHTTP2 {
+		return http.DefaultClient
+	}
+	client := &http.Client{Timeout: cfg.Timeout, Transport: cfg.Transport}
+	if cfg.Transport == nil && (cfg.MaxIdleConnsPerHost != 0 || cfg.DisableHTTP2) {
+		client.Transport = newTransport(cfg)
+	}
+	return client
+}
+
+func newTransport(cfg *Config) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		if t.MaxIdleConns != 0 && t.MaxIdleConns < cfg.MaxIdleConnsPerHost {
+			t.MaxIdleConns = cfg.MaxIdleConnsPerHost
+		}
+	}
+	if cfg.DisableHTTP2 {
+		t.ForceAttemptHTTP2 = false
+		// A non-nil, empty map disables the automatic HTTP/2 upgrade.
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	return t
+}
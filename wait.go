@@ -0,0 +1,66 @@
+package porkbun
+
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
+// Defaults for WaitOptions.
+const (
+	DEFAULT_WAIT_MIN_INTERVAL = 2 * time.Second
+	DEFAULT_WAIT_MAX_INTERVAL = 30 * time.Second
+)
+
+// WaitOptions controls how WaitForRecord polls. The interval starts at
+// MinInterval and doubles after each attempt up to MaxInterval; every sleep
+// is jittered so concurrent waiters spread out instead of polling in step.
+type WaitOptions struct {
+	MinInterval time.Duration
+	MaxInterval time.Duration
+}
+
+// WaitForRecord polls Porkbun until a record of the given type exists at
+// subdomain, with the given content unless content is empty, and returns
+// it. It gives up when ctx is done, returning the context's error. Failed
+// polls are retried like misses, so a transient API error does not end the
+// wait.
+func (c *Client) WaitForRecord(ctx context.Context, domain string, recordType string, subdomain string, content string, opts WaitOptions) (*DNSRecord, error) {
+	minInterval, maxInterval := opts.MinInterval, opts.MaxInterval
+	if minInterval <= 0 {
+		minInterval = DEFAULT_WAIT_MIN_INTERVAL
+	}
+	if maxInterval < minInterval {
+		maxInterval = DEFAULT_WAIT_MAX_INTERVAL
+		if maxInterval < minInterval {
+			maxInterval = minInterval
+		}
+	}
+	want := normalizeRecord(DNSRecord{Type: recordType, Content: content})
+	interval := minInterval
+	for {
+		records, err := c.retrieveRecordsByNameType(ctx, domain, recordType, subdomain)
+		if err == nil {
+			for _, record := range records {
+				if content == "" || normalizeRecord(*record).Content == want.Content {
+					return record, nil
+				}
+			}
+		}
+		if err := sleep(ctx, c.clock(), jitter(interval)); err != nil {
+			return nil, err
+		}
+		if interval *= 2; interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// jitter returns a random duration in [d/2, d).
+func jitter(d time.Duration) time.Duration {
+	half := d / 2
+	if half <= 0 {
+		return d
+	}
+	return half + time.Duration(rand.Int63n(int64(half)))
+}
@@ -0,0 +1,289 @@
+package porkbun
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseZoneFile reads records from a BIND-style zone file for domain, which
+// is also the initial $ORIGIN. It understands $ORIGIN and $TTL, "@",
+// relative and absolute names, omitted owner names, TTL units such as "1h",
+// comments and parenthesised multi-line records. Names and hostname targets
+// are returned relative to domain, MX and SRV priorities go to Prio and
+// quoted TXT strings are joined. SOA records are skipped since Porkbun
+// manages the SOA itself.
+func ParseZoneFile(r io.Reader, domain string) ([]*DNSRecord, error) {
+	p := zoneParser{
+		domain: strings.ToLower(strings.TrimSuffix(domain, ".")),
+		origin: strings.ToLower(strings.TrimSuffix(domain, ".")),
+	}
+	records := []*DNSRecord{}
+	scanner := bufio.NewScanner(r)
+	lineNo, pending, startLine, depth := 0, "", 0, 0
+	for scanner.Scan() {
+		lineNo++
+		line := stripZoneComment(scanner.Text())
+		if pending == "" {
+			startLine = lineNo
+		}
+		line, delta := stripZoneParens(line)
+		depth += delta
+		if depth < 0 {
+			return nil, fmt.Errorf("zone file line %d: unbalanced parentheses", lineNo)
+		}
+		if depth > 0 {
+			pending += line + " "
+			continue
+		}
+		if pending != "" {
+			line = pending + line
+			pending = ""
+		}
+		depth = 0
+		record, err := p.parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("zone file line %d: %w", startLine, err)
+		}
+		if record != nil {
+			records = append(records, record)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if pending != "" {
+		return nil, fmt.Errorf("zone file line %d: unbalanced parentheses", startLine)
+	}
+	return records, nil
+}
+
+type zoneParser struct {
+	domain   string
+	origin   string
+	ttl      string
+	lastName string
+}
+
+// parseLine parses one logical line, returning nil for directives, blank
+// lines and skipped records.
+func (p *zoneParser) parseLine(line string) (*DNSRecord, error) {
+	if strings.TrimSpace(line) == "" {
+		return nil, nil
+	}
+	fields := splitZoneFields(line)
+	switch strings.ToUpper(fields[0]) {
+	case "$ORIGIN":
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("$ORIGIN takes one argument")
+		}
+		p.origin = strings.ToLower(p.qualify(fields[1]))
+		return nil, nil
+	case "$TTL":
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("$TTL takes one argument")
+		}
+		ttl, err := parseZoneTTL(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		p.ttl = ttl
+		return nil, nil
+	case "$INCLUDE", "$GENERATE":
+		return nil, fmt.Errorf("%s is not supported", fields[0])
+	}
+
+	name := p.lastName
+	if line[0] != ' ' && line[0] != '\t' {
+		name = p.qualify(fields[0])
+		fields = fields[1:]
+	}
+	if name == "" {
+		return nil, fmt.Errorf("record without an owner name")
+	}
+	p.lastName = name
+
+	// TTL and class may come in either order before the type.
+	ttl := p.ttl
+	for len(fields) > 0 {
+		if strings.EqualFold(fields[0], "IN") {
+			fields = fields[1:]
+		} else if t, err := parseZoneTTL(fields[0]); err == nil {
+			ttl = t
+			fields = fields[1:]
+		} else {
+			break
+		}
+	}
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("record for %s has no data", name)
+	}
+	record := &DNSRecord{
+		Name: relativeName(name, p.domain),
+		Type: strings.ToUpper(fields[0]),
+		TTL:  ttl,
+	}
+	data := fields[1:]
+	switch record.Type {
+	case "SOA":
+		return nil, nil
+	case "MX":
+		if len(data) != 2 {
+			return nil, fmt.Errorf("MX record for %s must be \"priority host\"", name)
+		}
+		record.Prio = data[0]
+		record.Content = p.qualify(data[1])
+	case "SRV":
+		if len(data) != 4 {
+			return nil, fmt.Errorf("SRV record for %s must be \"priority weight port target\"", name)
+		}
+		record.Prio = data[0]
+		record.Content = data[1] + " " + data[2] + " " + p.qualify(data[3])
+	case "CNAME", "ALIAS", "NS":
+		if len(data) != 1 {
+			return nil, fmt.Errorf("%s record for %s must have a single target", record.Type, name)
+		}
+		record.Content = p.qualify(data[0])
+	case "TXT":
+		var parts []string
+		for _, part := range data {
+			parts = append(parts, unquoteZoneString(part))
+		}
+		record.Content = strings.Join(parts, "")
+	default:
+		record.Content = strings.Join(data, " ")
+	}
+	return record, nil
+}
+
+// qualify turns a zone file name into a fully qualified name without a
+// trailing dot, the form Porkbun stores hostname targets in.
+func (p *zoneParser) qualify(name string) string {
+	switch {
+	case name == "@":
+		return p.origin
+	case strings.HasSuffix(name, "."):
+		return strings.TrimSuffix(name, ".")
+	case p.origin == "":
+		return name
+	}
+	return name + "." + p.origin
+}
+
+// stripZoneComment removes a trailing ";" comment outside quotes.
+func stripZoneComment(line string) string {
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '"':
+			inQuote = !inQuote
+		case ';':
+			if !inQuote {
+				return line[:i]
+			}
+		}
+	}
+	return line
+}
+
+// stripZoneParens replaces the parentheses outside quotes in line with
+// spaces and returns the change in nesting depth they make, so parentheses
+// inside TXT strings are kept as content.
+func stripZoneParens(line string) (string, int) {
+	b := []byte(line)
+	depth := 0
+	inQuote := false
+	for i := 0; i < len(b); i++ {
+		switch b[i] {
+		case '\\':
+			i++
+		case '"':
+			inQuote = !inQuote
+		case '(', ')':
+			if inQuote {
+				continue
+			}
+			if b[i] == '(' {
+				depth++
+			} else {
+				depth--
+			}
+			b[i] = ' '
+		}
+	}
+	return string(b), depth
+}
+
+// splitZoneFields splits a line on whitespace, keeping quoted strings,
+// including their quotes, as single fields.
+func splitZoneFields(line string) []string {
+	var fields []string
+	var current strings.Builder
+	inQuote := false
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case ch == '\\' && i+1 < len(line):
+			current.WriteByte(ch)
+			current.WriteByte(line[i+1])
+			i++
+		case ch == '"':
+			inQuote = !inQuote
+			current.WriteByte(ch)
+		case (ch == ' ' || ch == '\t') && !inQuote:
+			if current.Len() > 0 {
+				fields = append(fields, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	if current.Len() > 0 {
+		fields = append(fields, current.String())
+	}
+	return fields
+}
+
+func unquoteZoneString(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	return strings.NewReplacer(`\"`, `"`, `\\`, `\`).Replace(s)
+}
+
+// parseZoneTTL parses a TTL given in seconds or with BIND's s, m, h, d and
+// w units (e.g. "1h30m"), returning it in seconds.
+func parseZoneTTL(s string) (string, error) {
+	if s == "" || s[0] < '0' || s[0] > '9' {
+		return "", fmt.Errorf("invalid TTL %q", s)
+	}
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil {
+		return strconv.FormatUint(n, 10), nil
+	}
+	units := map[byte]uint64{'s': 1, 'm': 60, 'h': 3600, 'd': 86400, 'w': 604800}
+	var total, n uint64
+	digits := false
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch >= '0' && ch <= '9' {
+			n = n*10 + uint64(ch-'0')
+			digits = true
+			continue
+		}
+		unit, ok := units[ch|0x20]
+		if !ok || !digits {
+			return "", fmt.Errorf("invalid TTL %q", s)
+		}
+		total += n * unit
+		n, digits = 0, false
+	}
+	if digits {
+		return "", fmt.Errorf("invalid TTL %q", s)
+	}
+	return strconv.FormatUint(total, 10), nil
+}
@@ -0,0 +1,109 @@
+package porkbun
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testZoneFile = `$ORIGIN example.com.
+$TTL 1h
+@	IN	SOA	ns1.example.com. hostmaster.example.com. (
+		2024010101 ; serial
+		7200 3600 1209600 300 )
+@		IN	MX	10 mx.example.com.
+www	300	IN	A	192.0.2.1
+	IN	AAAA	2001:db8::1
+note		TXT	"smile :) (really"   ; unbalanced inside quotes
+multi		TXT	( "v=DKIM1; k=rsa; "
+			  "p=MIGf(MA0)" )
+_sip._tcp	SRV	10 60 5060 sip
+blog		CNAME	ghs.example.net.
+`
+
+func TestParseZoneFile(t *testing.T) {
+	records, err := ParseZoneFile(strings.NewReader(testZoneFile), "example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*DNSRecord{
+		{Name: "", Type: "MX", Content: "mx.example.com", Prio: "10", TTL: "3600"},
+		{Name: "www", Type: "A", Content: "192.0.2.1", TTL: "300"},
+		{Name: "www", Type: "AAAA", Content: "2001:db8::1", TTL: "3600"},
+		{Name: "note", Type: "TXT", Content: "smile :) (really", TTL: "3600"},
+		{Name: "multi", Type: "TXT", Content: "v=DKIM1; k=rsa; p=MIGf(MA0)", TTL: "3600"},
+		{Name: "_sip._tcp", Type: "SRV", Content: "60 5060 sip.example.com", Prio: "10", TTL: "3600"},
+		{Name: "blog", Type: "CNAME", Content: "ghs.example.net", TTL: "3600"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("got %d records, want %d: %+v", len(records), len(want), records)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(records[i], want[i]) {
+			t.Errorf("record %d = %+v, want %+v", i, records[i], want[i])
+		}
+	}
+}
+
+func TestParseZoneFileErrors(t *testing.T) {
+	for _, zone := range []string{
+		"www A ( 192.0.2.1\n",
+		"www A 192.0.2.1 )\nmail A 192.0.2.2\n",
+		"$INCLUDE other.zone\n",
+		"\tA 192.0.2.1\n",
+	} {
+		if records, err := ParseZoneFile(strings.NewReader(zone), "example.com"); err == nil {
+			t.Errorf("%q: got %+v, want an error", zone, records)
+		}
+	}
+}
+
+func TestSyncFromFile(t *testing.T) {
+	zone := newFakeZone("example.com",
+		&DNSRecord{Name: "", Type: "NS", Content: "curitiba.ns.porkbun.com"},
+		&DNSRecord{Name: "www", Type: "A", Content: "192.0.2.9", TTL: "300"},
+		&DNSRecord{Name: "old", Type: "A", Content: "192.0.2.8"},
+	)
+	s, c := newTestServer(t, nil, zone.ServeHTTP)
+	path := filepath.Join(t.TempDir(), "example.com.zone")
+	file := "$ORIGIN example.com.\nwww 300 A 192.0.2.1\nnote TXT \"a (b\"\n"
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	dry, err := c.SyncFromFile(context.Background(), "example.com", path, ApplyOptions{DryRun: true, Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dry.Diff.Create) != 1 || len(dry.Diff.Update) != 1 || len(dry.Diff.Delete) != 2 || len(dry.Results) != 0 {
+		t.Errorf("dry run diff = %+v", dry.Diff)
+	}
+	if n := len(s.Requests()); n != 1 {
+		t.Errorf("dry run sent %d requests, want only the retrieve", n)
+	}
+
+	result, err := c.SyncFromFile(context.Background(), "example.com", path, ApplyOptions{Prune: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range result.Results {
+		if r.Err != nil {
+			t.Errorf("%s: %v", r.ID, r.Err)
+		}
+	}
+	got := map[string]string{}
+	for _, record := range zone.Records() {
+		got[record.Type+" "+nameKey(record.Name, "example.com")] = record.Content + " " + record.TTL
+	}
+	want := map[string]string{
+		"NS ":      "curitiba.ns.porkbun.com 600",
+		"A www":    "192.0.2.1 300",
+		"TXT note": "a (b 600",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("zone = %v, want %v", got, want)
+	}
+}